@@ -28,3 +28,8 @@ func NewContext(correlationId string) (context.Context, error) {
 func NewId() string {
 	return uuid.NewString()
 }
+
+// WithId returns a copy of ctx that carries the correlation idHeader.
+func WithId(ctx context.Context, correlationId string) context.Context {
+	return context.WithValue(ctx, idHeader, correlationId)
+}
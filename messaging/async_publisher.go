@@ -0,0 +1,399 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ammyy9908/go-common-libraries/gracefulshutdown"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultAsyncBufferSize      = 1000
+	DefaultAsyncFlushInterval   = 100 * time.Millisecond
+	DefaultAsyncShutdownTimeout = 10 * time.Second
+)
+
+var (
+	ErrBufferFull      = errors.New("async publisher buffer is full")
+	ErrMessageDropped  = errors.New("message dropped from a full async publisher buffer")
+	ErrPublisherClosed = errors.New("async publisher is closed")
+)
+
+// OverflowPolicy decides what AsyncPublisher.Publish does when the buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until there is room in the buffer, the context of the call ends or the publisher is closed.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered entry, which is reported to OnError with ErrMessageDropped.
+	OverflowDropOldest
+	// OverflowError returns ErrBufferFull.
+	OverflowError
+)
+
+// BatchPublisher is implemented by QueuePublisher and TopicPublisher. Use TopicsPublisher.Topic for a
+// TopicsPublisher.
+type BatchPublisher interface {
+	PublishBatch(ctx context.Context, entries []BatchEntry, opts ...Option) ([]BatchEntryResult, error)
+}
+
+// AsyncErrorFunc is called with every entry an AsyncPublisher could not publish.
+type AsyncErrorFunc func(entry BatchEntry, err error)
+
+type AsyncPublisherConfig struct {
+	// Logger logs the entries that could not be published when OnError is not set.
+	Logger *zap.SugaredLogger
+	// BufferSize is how many entries can wait to be published (DefaultAsyncBufferSize by default). Overflow decides
+	// what happens when it is full.
+	BufferSize int
+	Overflow   OverflowPolicy
+	// Workers is how many batches are published at the same time (1 by default). A worker publishes once it has
+	// MaxBatchEntries entries or FlushInterval (DefaultAsyncFlushInterval by default) after its first entry.
+	Workers       int
+	FlushInterval time.Duration
+	// RetryOptions are passed to PublishBatch.
+	RetryOptions []Option
+	OnError      AsyncErrorFunc
+	// GracefulShutdownManager is optional. When set, closing its ShutdownChannel closes the publisher and Shutdown
+	// waits for the buffered entries to be published, for at most ShutdownTimeout (DefaultAsyncShutdownTimeout by
+	// default).
+	GracefulShutdownManager *gracefulshutdown.Manager
+	ShutdownTimeout         time.Duration
+}
+
+// AsyncPublisher buffers entries in memory and publishes them in batches in the background, so that callers do not
+// wait for SNS or SQS. Buffered entries are lost if the process exits without Close.
+type AsyncPublisher struct {
+	publisher     BatchPublisher
+	logger        *zap.SugaredLogger
+	overflow      OverflowPolicy
+	flushInterval time.Duration
+	retryOptions  []Option
+	onError       AsyncErrorFunc
+	entries       chan asyncEntry
+	// ctx is cancelled when Close gives up, which aborts the batches being published.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+	// closeMu is held for reading while entries are added, so that Close does not close entries during a send.
+	// closing is closed first, so that a Publish blocked on a full buffer gives up the lock.
+	closeMu   sync.RWMutex
+	closed    bool
+	closing   chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+	// epoch is the generation new entries are counted in. Flush starts a new one and waits for the entries of the
+	// generations before it, so that entries added during Flush do not keep it waiting.
+	epoch   uint64
+	pending map[uint64]*pendingEntries
+	// flushing is closed while Flush is waiting, so that workers publish without waiting for FlushInterval.
+	flushing chan struct{}
+	flushers int
+}
+
+// asyncEntry is a buffered entry with the epoch it was added in.
+type asyncEntry struct {
+	entry BatchEntry
+	epoch uint64
+}
+
+// pendingEntries counts the entries of an epoch that were added but not yet published or reported. done is closed
+// once there are none left.
+type pendingEntries struct {
+	count int
+	done  chan struct{}
+}
+
+func NewAsyncPublisher(publisher BatchPublisher, config AsyncPublisherConfig) *AsyncPublisher {
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultAsyncBufferSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultAsyncFlushInterval
+	}
+	if config.Logger == nil {
+		config.Logger = zap.NewNop().Sugar()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &AsyncPublisher{
+		publisher:     publisher,
+		logger:        config.Logger,
+		overflow:      config.Overflow,
+		flushInterval: config.FlushInterval,
+		retryOptions:  config.RetryOptions,
+		onError:       config.OnError,
+		entries:       make(chan asyncEntry, config.BufferSize),
+		ctx:           ctx,
+		cancel:        cancel,
+		closing:       make(chan struct{}),
+		pending:       make(map[uint64]*pendingEntries),
+		flushing:      make(chan struct{}),
+	}
+	if p.onError == nil {
+		p.onError = p.logError
+	}
+
+	p.workers.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go p.work()
+	}
+	if config.GracefulShutdownManager != nil {
+		p.closeOnShutdown(config.GracefulShutdownManager, config.ShutdownTimeout)
+	}
+	return p
+}
+
+// Publish adds entry to the buffer. It returns once the entry is buffered, not published; failures to publish are
+// reported to OnError.
+func (p *AsyncPublisher) Publish(ctx context.Context, entry BatchEntry) error {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.closed {
+		return ErrPublisherClosed
+	}
+
+	buffered := asyncEntry{entry: entry, epoch: p.addPending()}
+	select {
+	case p.entries <- buffered:
+		return nil
+	default:
+	}
+
+	switch p.overflow {
+	case OverflowDropOldest:
+		for {
+			select {
+			case p.entries <- buffered:
+				return nil
+			default:
+			}
+			select {
+			case dropped := <-p.entries:
+				p.onError(dropped.entry, ErrMessageDropped)
+				p.removePending(dropped.epoch)
+			default:
+			}
+		}
+	case OverflowError:
+		p.removePending(buffered.epoch)
+		return ErrBufferFull
+	default:
+		select {
+		case p.entries <- buffered:
+			return nil
+		case <-ctx.Done():
+			p.removePending(buffered.epoch)
+			return ctx.Err()
+		case <-p.closing:
+			p.removePending(buffered.epoch)
+			return ErrPublisherClosed
+		}
+	}
+}
+
+// Flush publishes the buffered entries right away and waits until every entry added before the call has been
+// published or reported to OnError, or until ctx ends. Entries added while Flush waits are not waited for.
+func (p *AsyncPublisher) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	if len(p.pending) == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	waits := make([]<-chan struct{}, 0, len(p.pending))
+	for _, pending := range p.pending {
+		waits = append(waits, pending.done)
+	}
+	p.epoch++
+	p.flushers++
+	if p.flushers == 1 {
+		close(p.flushing)
+	}
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.flushers--
+		if p.flushers == 0 {
+			p.flushing = make(chan struct{})
+		}
+	}()
+
+	for _, done := range waits {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close stops accepting entries, makes a Publish blocked by OverflowBlock return ErrPublisherClosed and waits for
+// the buffered entries to be published. When ctx ends first, the batches
+// being published are aborted and every entry left is reported to OnError before Close returns the error of ctx.
+func (p *AsyncPublisher) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() { close(p.closing) })
+	p.closeMu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.entries)
+	}
+	p.closeMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.cancel()
+	<-done
+	return ctx.Err()
+}
+
+func (p *AsyncPublisher) closeOnShutdown(gracefulShutdownManager *gracefulshutdown.Manager, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultAsyncShutdownTimeout
+	}
+	gracefulShutdownManager.ShutdownWaitGroup.Add(1)
+	go func() {
+		defer gracefulShutdownManager.ShutdownWaitGroup.Done()
+		<-gracefulShutdownManager.ShutdownChannel
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err := p.Close(ctx)
+		if err != nil {
+			p.logger.Errorw("failed to publish buffered messages before shutdown", "error", err)
+		}
+	}()
+}
+
+func (p *AsyncPublisher) work() {
+	defer p.workers.Done()
+	for {
+		entry, ok := <-p.entries
+		if !ok {
+			return
+		}
+
+		batch := []asyncEntry{entry}
+		p.mu.Lock()
+		flushing := p.flushing
+		p.mu.Unlock()
+		timer := time.NewTimer(p.flushInterval)
+	collect:
+		for len(batch) < MaxBatchEntries {
+			select {
+			case entry, ok := <-p.entries:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, entry)
+			case <-timer.C:
+				break collect
+			case <-flushing:
+				// Flush does not wait for the interval, but still takes whatever is already buffered.
+				for len(batch) < MaxBatchEntries {
+					select {
+					case entry, ok := <-p.entries:
+						if !ok {
+							break collect
+						}
+						batch = append(batch, entry)
+					default:
+						break collect
+					}
+				}
+				break collect
+			}
+		}
+		timer.Stop()
+
+		p.publish(batch)
+	}
+}
+
+func (p *AsyncPublisher) publish(batch []asyncEntry) {
+	defer func() {
+		for _, buffered := range batch {
+			p.removePending(buffered.epoch)
+		}
+	}()
+
+	entries := make([]BatchEntry, len(batch))
+	for i, buffered := range batch {
+		entries[i] = buffered.entry
+	}
+
+	results, err := p.publisher.PublishBatch(p.ctx, entries, p.retryOptions...)
+	if len(results) != len(entries) {
+		for _, entry := range entries {
+			p.onError(entry, err)
+		}
+		return
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			p.onError(entries[i], result.Err)
+		}
+	}
+}
+
+// addPending counts an added entry in the current epoch and returns that epoch.
+func (p *AsyncPublisher) addPending() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pending, ok := p.pending[p.epoch]
+	if !ok {
+		pending = &pendingEntries{done: make(chan struct{})}
+		p.pending[p.epoch] = pending
+	}
+	pending.count++
+	return p.epoch
+}
+
+// removePending counts an entry of epoch as published or reported.
+func (p *AsyncPublisher) removePending(epoch uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pending := p.pending[epoch]
+	pending.count--
+	if pending.count == 0 {
+		close(pending.done)
+		delete(p.pending, epoch)
+	}
+}
+
+func (p *AsyncPublisher) logError(entry BatchEntry, err error) {
+	p.logger.Errorw("failed to publish message", "id", entry.ID, "subject", entry.Subject, "error", err)
+}
+
+// Topic returns a BatchPublisher that publishes to topicName, for use with NewAsyncPublisher.
+func (p *TopicsPublisher) Topic(topicName string) BatchPublisher {
+	return &topicBatchPublisher{publisher: p, topicName: topicName}
+}
+
+type topicBatchPublisher struct {
+	publisher *TopicsPublisher
+	topicName string
+}
+
+func (p *topicBatchPublisher) PublishBatch(ctx context.Context, entries []BatchEntry, opts ...Option) ([]BatchEntryResult, error) {
+	return p.publisher.PublishBatch(ctx, p.topicName, entries, opts...)
+}
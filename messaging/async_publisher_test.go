@@ -0,0 +1,351 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ammyy9908/go-common-libraries/gracefulshutdown"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snsTypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeBatchPublisher records the batches it publishes. Entries whose message is in fail are reported as failed and
+// every call waits for release when it is set.
+type fakeBatchPublisher struct {
+	mu      sync.Mutex
+	batches [][]BatchEntry
+	fail    map[string]error
+	release chan struct{}
+}
+
+func (f *fakeBatchPublisher) PublishBatch(ctx context.Context, entries []BatchEntry, _ ...Option) ([]BatchEntryResult, error) {
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, entries)
+
+	var err error
+	results := make([]BatchEntryResult, len(entries))
+	for i, entry := range entries {
+		results[i].ID = strconv.Itoa(i)
+		switch {
+		case ctx.Err() != nil:
+			results[i].Err = ctx.Err()
+		case f.fail[entry.Message] != nil:
+			results[i].Err = f.fail[entry.Message]
+		}
+		if results[i].Err != nil {
+			err = ErrPublishBatchFailed
+		}
+	}
+	return results, err
+}
+
+func (f *fakeBatchPublisher) published() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var messages []string
+	for _, batch := range f.batches {
+		for _, entry := range batch {
+			messages = append(messages, entry.Message)
+		}
+	}
+	return messages
+}
+
+type errorRecorder struct {
+	mu     sync.Mutex
+	errors map[string]error
+}
+
+func (r *errorRecorder) record(entry BatchEntry, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.errors == nil {
+		r.errors = make(map[string]error)
+	}
+	r.errors[entry.Message] = err
+}
+
+func (r *errorRecorder) get() map[string]error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.errors
+}
+
+type batchPublisherFunc func(ctx context.Context, entries []BatchEntry) ([]BatchEntryResult, error)
+
+func (f batchPublisherFunc) PublishBatch(ctx context.Context, entries []BatchEntry, _ ...Option) ([]BatchEntryResult, error) {
+	return f(ctx, entries)
+}
+
+func pendingCount(p *AsyncPublisher) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	count := 0
+	for _, pending := range p.pending {
+		count += pending.count
+	}
+	return count
+}
+
+func TestAsyncPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Flush publishes the buffered entries in batches of at most MaxBatchEntries", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{FlushInterval: time.Hour})
+		defer sut.Close(ctx)
+
+		var expected []string
+		for i := 0; i < 15; i++ {
+			expected = append(expected, strconv.Itoa(i))
+			assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: strconv.Itoa(i)}))
+		}
+
+		assert.Nil(t, sut.Flush(ctx))
+		assert.Equal(t, expected, publisher.published())
+		assert.Len(t, publisher.batches[0], MaxBatchEntries)
+	})
+
+	t.Run("Flush does not wait for entries added after it was called", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		publisher := batchPublisherFunc(func(ctx context.Context, entries []BatchEntry) ([]BatchEntryResult, error) {
+			if entries[0].Message == "first" {
+				close(started)
+				<-release
+			} else {
+				<-ctx.Done()
+			}
+			return make([]BatchEntryResult, len(entries)), nil
+		})
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{Workers: 2, FlushInterval: time.Hour})
+		closeCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		defer sut.Close(closeCtx)
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "first"}))
+		flushCtx, cancelFlush := context.WithTimeout(ctx, time.Second)
+		defer cancelFlush()
+		flushed := make(chan error)
+
+		go func() { flushed <- sut.Flush(flushCtx) }()
+		<-started
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "later"}))
+		close(release)
+
+		assert.Nil(t, <-flushed)
+	})
+
+	t.Run("a partial batch is published after FlushInterval", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{FlushInterval: 10 * time.Millisecond})
+		defer sut.Close(ctx)
+
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "message"}))
+
+		assert.Eventually(t, func() bool {
+			return len(publisher.published()) == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("entries that could not be published are reported to OnError", func(t *testing.T) {
+		publishErr := errors.New("publish error")
+		publisher := &fakeBatchPublisher{fail: map[string]error{"bad": publishErr}}
+		recorder := &errorRecorder{}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{OnError: recorder.record})
+		defer sut.Close(ctx)
+
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "good"}))
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "bad"}))
+
+		assert.Nil(t, sut.Flush(ctx))
+		assert.Equal(t, map[string]error{"bad": publishErr}, recorder.get())
+	})
+
+	t.Run("OverflowError returns ErrBufferFull when the buffer is full", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{release: make(chan struct{})}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{BufferSize: 1, Overflow: OverflowError, FlushInterval: time.Millisecond})
+		defer sut.Close(ctx)
+
+		// The worker holds the first entry while it waits for release, the second one fills the buffer.
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "first"}))
+		assert.Eventually(t, func() bool { return len(sut.entries) == 0 }, time.Second, time.Millisecond)
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "second"}))
+
+		assert.ErrorIs(t, sut.Publish(ctx, BatchEntry{Message: "third"}), ErrBufferFull)
+
+		close(publisher.release)
+		assert.Nil(t, sut.Flush(ctx))
+		assert.Equal(t, []string{"first", "second"}, publisher.published())
+	})
+
+	t.Run("OverflowDropOldest replaces the oldest buffered entry and reports it", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{release: make(chan struct{})}
+		recorder := &errorRecorder{}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{BufferSize: 1, Overflow: OverflowDropOldest, FlushInterval: time.Millisecond, OnError: recorder.record})
+		defer sut.Close(ctx)
+
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "first"}))
+		assert.Eventually(t, func() bool { return len(sut.entries) == 0 }, time.Second, time.Millisecond)
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "second"}))
+
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "third"}))
+
+		close(publisher.release)
+		assert.Nil(t, sut.Flush(ctx))
+		assert.Equal(t, []string{"first", "third"}, publisher.published())
+		assert.Equal(t, map[string]error{"second": ErrMessageDropped}, recorder.get())
+	})
+
+	t.Run("OverflowBlock waits for room in the buffer until the context ends", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{release: make(chan struct{})}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{BufferSize: 1, FlushInterval: time.Millisecond})
+		defer sut.Close(ctx)
+
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "first"}))
+		assert.Eventually(t, func() bool { return len(sut.entries) == 0 }, time.Second, time.Millisecond)
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "second"}))
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, sut.Publish(timeoutCtx, BatchEntry{Message: "third"}), context.DeadlineExceeded)
+
+		close(publisher.release)
+		assert.Nil(t, sut.Flush(ctx))
+	})
+
+	t.Run("Close publishes the buffered entries and rejects new ones", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{FlushInterval: time.Hour})
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "message"}))
+
+		assert.Nil(t, sut.Close(ctx))
+
+		assert.Equal(t, []string{"message"}, publisher.published())
+		assert.ErrorIs(t, sut.Publish(ctx, BatchEntry{Message: "late"}), ErrPublisherClosed)
+	})
+
+	t.Run("Close does not wait for a Publish blocked on a full buffer", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{release: make(chan struct{})}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{BufferSize: 1, FlushInterval: time.Millisecond})
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "first"}))
+		assert.Eventually(t, func() bool { return len(sut.entries) == 0 }, time.Second, time.Millisecond)
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "second"}))
+		blocked := make(chan error)
+		go func() { blocked <- sut.Publish(ctx, BatchEntry{Message: "third"}) }()
+		assert.Eventually(t, func() bool { return pendingCount(sut) == 3 }, time.Second, time.Millisecond)
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		closed := make(chan error)
+
+		go func() { closed <- sut.Close(timeoutCtx) }()
+
+		select {
+		case err := <-closed:
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(time.Second):
+			t.Fatal("Close waited for the blocked Publish")
+		}
+		assert.ErrorIs(t, <-blocked, ErrPublisherClosed)
+	})
+
+	t.Run("Close aborts publishing and reports the entries left when ctx ends", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{release: make(chan struct{})}
+		recorder := &errorRecorder{}
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{OnError: recorder.record})
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "message"}))
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		err := sut.Close(timeoutCtx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, recorder.get()["message"], context.Canceled)
+	})
+
+	t.Run("a graceful shutdown closes the publisher before Shutdown returns", func(t *testing.T) {
+		publisher := &fakeBatchPublisher{}
+		gsm := gracefulshutdown.NewManager(zap.NewNop().Sugar(), make(chan struct{}))
+		sut := NewAsyncPublisher(publisher, AsyncPublisherConfig{FlushInterval: time.Hour, GracefulShutdownManager: gsm})
+		assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: "message"}))
+
+		close(gsm.ShutdownChannel)
+		gsm.ShutdownWaitGroup.Wait()
+
+		assert.Equal(t, []string{"message"}, publisher.published())
+		assert.ErrorIs(t, sut.Publish(ctx, BatchEntry{Message: "late"}), ErrPublisherClosed)
+	})
+}
+
+func TestTopicsPublisher_Topic(t *testing.T) {
+	t.Run("Topic publishes batches to the topic with the given name", func(t *testing.T) {
+		snsClient, publisher, testTopic := setup(t)
+		snsClient.
+			EXPECT().
+			PublishBatch(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sns.PublishBatchInput, _ ...func(*sns.Options)) (*sns.PublishBatchOutput, error) {
+				assert.Equal(t, "test-arn", aws.ToString(input.TopicArn))
+				return &sns.PublishBatchOutput{Successful: []snsTypes.PublishBatchResultEntry{{Id: aws.String("0"), MessageId: aws.String("id")}}}, nil
+			})
+
+		results, err := publisher.Topic(testTopic).PublishBatch(context.Background(), []BatchEntry{{Message: "message"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "id", results[0].MessageID)
+	})
+
+	t.Run("Topic resolves the topic safely from several async publisher workers", func(t *testing.T) {
+		snsClient := NewMockISNSClient(gomock.NewController(t))
+		publisher := &TopicsPublisher{snsClient: snsClient, topicsCache: make(map[string]string)}
+		snsClient.
+			EXPECT().
+			CreateTopic(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, *sns.CreateTopicInput, ...func(*sns.Options)) (*sns.CreateTopicOutput, error) {
+				time.Sleep(5 * time.Millisecond)
+				return &sns.CreateTopicOutput{TopicArn: aws.String("test-arn")}, nil
+			}).
+			MinTimes(1)
+		snsClient.
+			EXPECT().
+			PublishBatch(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sns.PublishBatchInput, _ ...func(*sns.Options)) (*sns.PublishBatchOutput, error) {
+				assert.Equal(t, "test-arn", aws.ToString(input.TopicArn))
+				output := &sns.PublishBatchOutput{}
+				for _, entry := range input.PublishBatchRequestEntries {
+					output.Successful = append(output.Successful, snsTypes.PublishBatchResultEntry{Id: entry.Id, MessageId: aws.String("id")})
+				}
+				return output, nil
+			}).
+			AnyTimes()
+		failed := new(atomic.Int64)
+		sut := NewAsyncPublisher(publisher.Topic("test-topic"), AsyncPublisherConfig{
+			Workers:       4,
+			FlushInterval: time.Millisecond,
+			OnError:       func(BatchEntry, error) { failed.Add(1) },
+		})
+		ctx := context.Background()
+
+		for i := 0; i < 100; i++ {
+			assert.Nil(t, sut.Publish(ctx, BatchEntry{Message: strconv.Itoa(i)}))
+		}
+
+		assert.Nil(t, sut.Close(ctx))
+		assert.Zero(t, failed.Load())
+	})
+}
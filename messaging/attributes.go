@@ -0,0 +1,234 @@
+package zaws
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	snsTypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/goccy/go-json"
+)
+
+const (
+	AttributeTypeString      = "String"
+	AttributeTypeNumber      = "Number"
+	AttributeTypeBinary      = "Binary"
+	AttributeTypeStringArray = "String.Array"
+)
+
+var (
+	ErrAttributeNotFound = errors.New("message attribute not found")
+	ErrAttributeType     = errors.New("message attribute has another data type")
+)
+
+// AttributeValue is a typed message attribute. BinaryValue is only set for the Binary data type, StringValue for
+// every other one.
+type AttributeValue struct {
+	DataType    string
+	StringValue string
+	BinaryValue []byte
+}
+
+// Attributes are typed message attributes, which SNS subscription filter policies can match on. Build them with
+// NewAttributes and pass them to a publisher with WithAttributes:
+//
+//	attributes := NewAttributes().String("country", "IN").Int("amount", 1200).StringArray("tags", []string{"new"})
+type Attributes map[string]AttributeValue
+
+func NewAttributes() Attributes {
+	return make(Attributes)
+}
+
+// StringAttributes converts plain string attributes.
+func StringAttributes(attributes map[string]string) Attributes {
+	result := make(Attributes, len(attributes))
+	for name, value := range attributes {
+		result.String(name, value)
+	}
+	return result
+}
+
+func (a Attributes) String(name, value string) Attributes {
+	a[name] = AttributeValue{DataType: AttributeTypeString, StringValue: value}
+	return a
+}
+
+func (a Attributes) Int(name string, value int64) Attributes {
+	a[name] = AttributeValue{DataType: AttributeTypeNumber, StringValue: strconv.FormatInt(value, 10)}
+	return a
+}
+
+func (a Attributes) Float(name string, value float64) Attributes {
+	a[name] = AttributeValue{DataType: AttributeTypeNumber, StringValue: strconv.FormatFloat(value, 'f', -1, 64)}
+	return a
+}
+
+func (a Attributes) Binary(name string, value []byte) Attributes {
+	a[name] = AttributeValue{DataType: AttributeTypeBinary, BinaryValue: value}
+	return a
+}
+
+// StringArray sets an attribute SNS filter policies match when any of the values matches. SQS keeps it as a custom
+// String type.
+func (a Attributes) StringArray(name string, values []string) Attributes {
+	if values == nil {
+		values = []string{}
+	}
+	encoded, _ := json.Marshal(values)
+	a[name] = AttributeValue{DataType: AttributeTypeStringArray, StringValue: string(encoded)}
+	return a
+}
+
+// GetString returns the value of a String attribute, or of any custom String type.
+func (a Attributes) GetString(name string) (string, error) {
+	value, err := a.get(name, AttributeTypeString)
+	return value.StringValue, err
+}
+
+func (a Attributes) GetInt(name string) (int64, error) {
+	value, err := a.get(name, AttributeTypeNumber)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value.StringValue, 10, 64)
+}
+
+func (a Attributes) GetFloat(name string) (float64, error) {
+	value, err := a.get(name, AttributeTypeNumber)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(value.StringValue, 64)
+}
+
+func (a Attributes) GetBinary(name string) ([]byte, error) {
+	value, err := a.get(name, AttributeTypeBinary)
+	return value.BinaryValue, err
+}
+
+func (a Attributes) GetStringArray(name string) ([]string, error) {
+	value, err := a.get(name, AttributeTypeStringArray)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	err = json.Unmarshal([]byte(value.StringValue), &values)
+	return values, err
+}
+
+// get returns the attribute when its data type is dataType or a custom type based on it, such as Number.Money.
+func (a Attributes) get(name, dataType string) (AttributeValue, error) {
+	value, ok := a[name]
+	if !ok {
+		return AttributeValue{}, fmt.Errorf("%w: %s", ErrAttributeNotFound, name)
+	}
+	if value.DataType != dataType && !strings.HasPrefix(value.DataType, dataType+".") {
+		return AttributeValue{}, fmt.Errorf("%w: %s is %s, not %s", ErrAttributeType, name, value.DataType, dataType)
+	}
+	return value, nil
+}
+
+// with returns the attributes together with others, which take precedence. It returns nil when both are empty.
+func (a Attributes) with(others Attributes) Attributes {
+	if len(a)+len(others) == 0 {
+		return nil
+	}
+	result := make(Attributes, len(a)+len(others))
+	for name, value := range a {
+		result[name] = value
+	}
+	for name, value := range others {
+		result[name] = value
+	}
+	return result
+}
+
+// size is the size SNS and SQS count against the message size limit: the name, type and value of every attribute.
+func (a Attributes) size() int {
+	size := 0
+	for name, value := range a {
+		size += len(name) + len(value.DataType) + len(value.StringValue) + len(value.BinaryValue)
+	}
+	return size
+}
+
+func (a Attributes) sqs() map[string]sqsTypes.MessageAttributeValue {
+	if len(a) == 0 {
+		return nil
+	}
+	values := make(map[string]sqsTypes.MessageAttributeValue, len(a))
+	for name, value := range a {
+		attribute := sqsTypes.MessageAttributeValue{DataType: aws.String(value.DataType)}
+		if value.BinaryValue != nil {
+			attribute.BinaryValue = value.BinaryValue
+		} else {
+			attribute.StringValue = aws.String(value.StringValue)
+		}
+		values[name] = attribute
+	}
+	return values
+}
+
+func (a Attributes) sns() map[string]snsTypes.MessageAttributeValue {
+	if len(a) == 0 {
+		return nil
+	}
+	values := make(map[string]snsTypes.MessageAttributeValue, len(a))
+	for name, value := range a {
+		attribute := snsTypes.MessageAttributeValue{DataType: aws.String(value.DataType)}
+		if value.BinaryValue != nil {
+			attribute.BinaryValue = value.BinaryValue
+		} else {
+			attribute.StringValue = aws.String(value.StringValue)
+		}
+		values[name] = attribute
+	}
+	return values
+}
+
+// snsEnvelope is the part of an SNS notification delivered to SQS without raw message delivery that carries the
+// message attributes.
+type snsEnvelope struct {
+	MessageAttributes map[string]struct {
+		Type  string `json:"Type"`
+		Value string `json:"Value"`
+	} `json:"MessageAttributes"`
+}
+
+// DecodeAttributes returns the typed attributes of a received message. Messages published to a topic without raw
+// message delivery carry their attributes in the SNS notification instead, which is read when the message itself
+// has none.
+func DecodeAttributes(message sqsTypes.Message) (Attributes, error) {
+	attributes := make(Attributes, len(message.MessageAttributes))
+	for name, value := range message.MessageAttributes {
+		attributes[name] = AttributeValue{
+			DataType:    aws.ToString(value.DataType),
+			StringValue: aws.ToString(value.StringValue),
+			BinaryValue: value.BinaryValue,
+		}
+	}
+	if len(attributes) > 0 || message.Body == nil {
+		return attributes, nil
+	}
+
+	var envelope snsEnvelope
+	if json.Unmarshal([]byte(*message.Body), &envelope) != nil {
+		return attributes, nil
+	}
+	for name, value := range envelope.MessageAttributes {
+		if value.Type != AttributeTypeBinary {
+			attributes[name] = AttributeValue{DataType: value.Type, StringValue: value.Value}
+			continue
+		}
+		binary, err := base64.StdEncoding.DecodeString(value.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid binary attribute %s: %w", name, err)
+		}
+		attributes[name] = AttributeValue{DataType: value.Type, BinaryValue: binary}
+	}
+	return attributes, nil
+}
@@ -0,0 +1,152 @@
+package zaws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	snsTypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributes(t *testing.T) {
+	t.Run("the builder sets the data type of every attribute", func(t *testing.T) {
+		attributes := NewAttributes().
+			String("country", "IN").
+			Int("amount", 1200).
+			Float("rate", 0.25).
+			Binary("payload", []byte{1, 2}).
+			StringArray("tags", []string{"new", "vip"})
+
+		assert.Equal(t, Attributes{
+			"country": {DataType: AttributeTypeString, StringValue: "IN"},
+			"amount":  {DataType: AttributeTypeNumber, StringValue: "1200"},
+			"rate":    {DataType: AttributeTypeNumber, StringValue: "0.25"},
+			"payload": {DataType: AttributeTypeBinary, BinaryValue: []byte{1, 2}},
+			"tags":    {DataType: AttributeTypeStringArray, StringValue: `["new","vip"]`},
+		}, attributes)
+	})
+
+	t.Run("the getters return the typed values", func(t *testing.T) {
+		attributes := NewAttributes().
+			String("country", "IN").
+			Int("amount", 1200).
+			Float("rate", 0.25).
+			Binary("payload", []byte{1, 2}).
+			StringArray("tags", []string{"new", "vip"})
+
+		country, err := attributes.GetString("country")
+		assert.Nil(t, err)
+		assert.Equal(t, "IN", country)
+		amount, err := attributes.GetInt("amount")
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1200), amount)
+		rate, err := attributes.GetFloat("rate")
+		assert.Nil(t, err)
+		assert.Equal(t, 0.25, rate)
+		payload, err := attributes.GetBinary("payload")
+		assert.Nil(t, err)
+		assert.Equal(t, []byte{1, 2}, payload)
+		tags, err := attributes.GetStringArray("tags")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"new", "vip"}, tags)
+	})
+
+	t.Run("the getters accept custom data types and reject other types", func(t *testing.T) {
+		attributes := Attributes{"price": {DataType: "Number.EUR", StringValue: "9.5"}}
+
+		price, err := attributes.GetFloat("price")
+		assert.Nil(t, err)
+		assert.Equal(t, 9.5, price)
+
+		_, err = attributes.GetString("price")
+		assert.ErrorIs(t, err, ErrAttributeType)
+		_, err = attributes.GetString("missing")
+		assert.ErrorIs(t, err, ErrAttributeNotFound)
+	})
+
+	t.Run("StringAttributes gives every attribute its own value", func(t *testing.T) {
+		attributes := StringAttributes(map[string]string{"a": "1", "b": "2"}).sqs()
+
+		assert.Equal(t, map[string]sqsTypes.MessageAttributeValue{
+			"a": {DataType: aws.String("String"), StringValue: aws.String("1")},
+			"b": {DataType: aws.String("String"), StringValue: aws.String("2")},
+		}, attributes)
+	})
+
+	t.Run("sns converts binary and string values", func(t *testing.T) {
+		attributes := NewAttributes().Int("amount", 3).Binary("payload", []byte{1}).sns()
+
+		assert.Equal(t, map[string]snsTypes.MessageAttributeValue{
+			"amount":  {DataType: aws.String("Number"), StringValue: aws.String("3")},
+			"payload": {DataType: aws.String("Binary"), BinaryValue: []byte{1}},
+		}, attributes)
+	})
+
+	t.Run("with lets the other attributes take precedence and is nil when both are empty", func(t *testing.T) {
+		attributes := StringAttributes(map[string]string{"a": "1", "b": "2"}).with(NewAttributes().Int("b", 3))
+
+		assert.Equal(t, Attributes{
+			"a": {DataType: AttributeTypeString, StringValue: "1"},
+			"b": {DataType: AttributeTypeNumber, StringValue: "3"},
+		}, attributes)
+		assert.Nil(t, StringAttributes(nil).with(nil))
+	})
+}
+
+func TestDecodeAttributes(t *testing.T) {
+	t.Run("DecodeAttributes reads the attributes of the message", func(t *testing.T) {
+		message := sqsTypes.Message{
+			Body: aws.String("body"),
+			MessageAttributes: map[string]sqsTypes.MessageAttributeValue{
+				"amount":  {DataType: aws.String("Number"), StringValue: aws.String("12")},
+				"payload": {DataType: aws.String("Binary"), BinaryValue: []byte{1}},
+			},
+		}
+
+		attributes, err := DecodeAttributes(message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Attributes{
+			"amount":  {DataType: AttributeTypeNumber, StringValue: "12"},
+			"payload": {DataType: AttributeTypeBinary, BinaryValue: []byte{1}},
+		}, attributes)
+	})
+
+	t.Run("DecodeAttributes reads the attributes of an SNS notification", func(t *testing.T) {
+		message := sqsTypes.Message{Body: aws.String(`{
+			"Type": "Notification",
+			"Subject": "test-topic",
+			"Message": "body",
+			"MessageAttributes": {
+				"amount": {"Type": "Number", "Value": "12"},
+				"tags": {"Type": "String.Array", "Value": "[\"new\"]"},
+				"payload": {"Type": "Binary", "Value": "AQ=="}
+			}
+		}`)}
+
+		attributes, err := DecodeAttributes(message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Attributes{
+			"amount":  {DataType: AttributeTypeNumber, StringValue: "12"},
+			"tags":    {DataType: AttributeTypeStringArray, StringValue: `["new"]`},
+			"payload": {DataType: AttributeTypeBinary, BinaryValue: []byte{1}},
+		}, attributes)
+	})
+
+	t.Run("DecodeAttributes returns no attributes for a plain message", func(t *testing.T) {
+		attributes, err := DecodeAttributes(sqsTypes.Message{Body: aws.String("not json")})
+
+		assert.Nil(t, err)
+		assert.Empty(t, attributes)
+	})
+
+	t.Run("DecodeAttributes fails on an invalid binary attribute", func(t *testing.T) {
+		message := sqsTypes.Message{Body: aws.String(`{"MessageAttributes": {"payload": {"Type": "Binary", "Value": "%"}}}`)}
+
+		_, err := DecodeAttributes(message)
+
+		assert.NotNil(t, err)
+	})
+}
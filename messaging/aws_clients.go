@@ -16,12 +16,16 @@ type ISQSClient interface {
 	SendMessage(ctx context.Context, params *sqs.SendMessageInput, options ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, options ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, options ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, options ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, options ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, options ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
 }
 
 type ISNSClient interface {
 	CreateTopic(ctx context.Context, params *sns.CreateTopicInput, options ...func(*sns.Options)) (*sns.CreateTopicOutput, error)
 	Subscribe(ctx context.Context, params *sns.SubscribeInput, options ...func(*sns.Options)) (*sns.SubscribeOutput, error)
 	Publish(ctx context.Context, params *sns.PublishInput, options ...func(*sns.Options)) (*sns.PublishOutput, error)
+	PublishBatch(ctx context.Context, params *sns.PublishBatchInput, options ...func(*sns.Options)) (*sns.PublishBatchOutput, error)
 }
 
 type MessageHandler interface {
@@ -2,7 +2,7 @@
 // Source: messaging/zaws/aws_clients.go
 
 // Package mock_zaws is a generated GoMock package.
-package mock
+package zaws
 
 import (
 	context "context"
@@ -37,6 +37,26 @@ func (m *MockISQSClient) EXPECT() *MockISQSClientMockRecorder {
 	return m.recorder
 }
 
+// ChangeMessageVisibility mocks base method.
+func (m *MockISQSClient) ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, options ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChangeMessageVisibility", varargs...)
+	ret0, _ := ret[0].(*sqs.ChangeMessageVisibilityOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeMessageVisibility indicates an expected call of ChangeMessageVisibility.
+func (mr *MockISQSClientMockRecorder) ChangeMessageVisibility(ctx, params interface{}, options ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMessageVisibility", reflect.TypeOf((*MockISQSClient)(nil).ChangeMessageVisibility), varargs...)
+}
+
 // CreateQueue mocks base method.
 func (m *MockISQSClient) CreateQueue(ctx context.Context, params *sqs.CreateQueueInput, options ...func(*sqs.Options)) (*sqs.CreateQueueOutput, error) {
 	m.ctrl.T.Helper()
@@ -77,6 +97,26 @@ func (mr *MockISQSClientMockRecorder) DeleteMessage(ctx, params interface{}, opt
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockISQSClient)(nil).DeleteMessage), varargs...)
 }
 
+// DeleteMessageBatch mocks base method.
+func (m *MockISQSClient) DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, options ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteMessageBatch", varargs...)
+	ret0, _ := ret[0].(*sqs.DeleteMessageBatchOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMessageBatch indicates an expected call of DeleteMessageBatch.
+func (mr *MockISQSClientMockRecorder) DeleteMessageBatch(ctx, params interface{}, options ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessageBatch", reflect.TypeOf((*MockISQSClient)(nil).DeleteMessageBatch), varargs...)
+}
+
 // GetQueueAttributes mocks base method.
 func (m *MockISQSClient) GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, options ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error) {
 	m.ctrl.T.Helper()
@@ -157,6 +197,26 @@ func (mr *MockISQSClientMockRecorder) SendMessage(ctx, params interface{}, optio
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockISQSClient)(nil).SendMessage), varargs...)
 }
 
+// SendMessageBatch mocks base method.
+func (m *MockISQSClient) SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, options ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SendMessageBatch", varargs...)
+	ret0, _ := ret[0].(*sqs.SendMessageBatchOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMessageBatch indicates an expected call of SendMessageBatch.
+func (mr *MockISQSClientMockRecorder) SendMessageBatch(ctx, params interface{}, options ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageBatch", reflect.TypeOf((*MockISQSClient)(nil).SendMessageBatch), varargs...)
+}
+
 // SetQueueAttributes mocks base method.
 func (m *MockISQSClient) SetQueueAttributes(ctx context.Context, params *sqs.SetQueueAttributesInput, options ...func(*sqs.Options)) (*sqs.SetQueueAttributesOutput, error) {
 	m.ctrl.T.Helper()
@@ -240,6 +300,26 @@ func (mr *MockISNSClientMockRecorder) Publish(ctx, params interface{}, options .
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockISNSClient)(nil).Publish), varargs...)
 }
 
+// PublishBatch mocks base method.
+func (m *MockISNSClient) PublishBatch(ctx context.Context, params *sns.PublishBatchInput, options ...func(*sns.Options)) (*sns.PublishBatchOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, params}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishBatch", varargs...)
+	ret0, _ := ret[0].(*sns.PublishBatchOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishBatch indicates an expected call of PublishBatch.
+func (mr *MockISNSClientMockRecorder) PublishBatch(ctx, params interface{}, options ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, params}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishBatch", reflect.TypeOf((*MockISNSClient)(nil).PublishBatch), varargs...)
+}
+
 // Subscribe mocks base method.
 func (m *MockISNSClient) Subscribe(ctx context.Context, params *sns.SubscribeInput, options ...func(*sns.Options)) (*sns.SubscribeOutput, error) {
 	m.ctrl.T.Helper()
@@ -83,7 +83,7 @@ func Test_Sns_Aws(t *testing.T) {
 		err = topicPublisher.Publish(messageBody)
 		assert.Nil(t, err)
 
-		go listener.Listen()
+		go listener.Listen(context.Background())
 		time.Sleep(1 * time.Second)
 
 		assert.Equal(t, messageBody, *handler.message.Body)
@@ -116,7 +116,7 @@ func Test_Aws_Sqs_With_Listener(t *testing.T) {
 
 		message := sendMessageToQueue(t, client, "test-message", queueURL)
 
-		go listener.Listen()
+		go listener.Listen(context.Background())
 
 		<-channel
 		gsm.ShutdownChannel <- 0
@@ -174,17 +174,17 @@ func Test_Aws_Sqs_With_Multiple_Queues(t *testing.T) {
 		queueURL2 := *queue2.QueueUrl
 		queueURL3 := *queue3.QueueUrl
 
-		listener := *createTestListenerForSqs(queueURL, log, gsm, handler, client.sqsClient, initQ1)
-		listener2 := *createTestListenerForSqs(queueURL2, log, gsm, handler2, client.sqsClient, testQ2)
-		listener3 := *createTestListenerForSqs(queueURL3, log, gsm, handler3, client.sqsClient, testQ3)
+		listener := createTestListenerForSqs(queueURL, log, gsm, handler, client.sqsClient, initQ1)
+		listener2 := createTestListenerForSqs(queueURL2, log, gsm, handler2, client.sqsClient, testQ2)
+		listener3 := createTestListenerForSqs(queueURL3, log, gsm, handler3, client.sqsClient, testQ3)
 
 		message := sendMessageToQueue(t, client, "test-message", queueURL)
 		message2 := sendMessageToQueue(t, client, "test-message2", queueURL2)
 		message3 := sendMessageToQueue(t, client, "test-message3", queueURL3)
 
-		go listener3.Listen()
-		go listener2.Listen()
-		go listener.Listen()
+		go listener3.Listen(context.Background())
+		go listener2.Listen(context.Background())
+		go listener.Listen(context.Background())
 
 		<-channel
 		<-channel2
@@ -0,0 +1,94 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const errNoBatchResult = "batch handler did not report a result for the message"
+
+// BatchMessageHandler handles every message returned by a single ReceiveMessage call at once. When the handler
+// configured on SQSListener also implements it, HandleBatch is used instead of Handle. Only messages reported as
+// succeeded are deleted, every other message is left on the queue for redelivery. The context is cancelled
+// DrainTimeout after the listener stops, so in-flight batches can finish until the drain deadline.
+type BatchMessageHandler interface {
+	HandleBatch(ctx context.Context, messages []types.Message) BatchResult
+}
+
+// BatchResult reports the outcome of HandleBatch by message ID.
+type BatchResult struct {
+	Succeeded []string
+	Failed    map[string]error
+}
+
+func (r *BatchResult) AddSuccess(messageID string) {
+	r.Succeeded = append(r.Succeeded, messageID)
+}
+
+func (r *BatchResult) AddFailure(messageID string, err error) {
+	if r.Failed == nil {
+		r.Failed = make(map[string]error)
+	}
+	r.Failed[messageID] = err
+}
+
+func (l *SQSListener) handleBatch(handler BatchMessageHandler, messages []types.Message) {
+	stopHeartbeats := make([]func(), 0, len(messages))
+	for _, message := range messages {
+		stopHeartbeats = append(stopHeartbeats, l.startVisibilityHeartbeat(l.handlersContext(), message))
+	}
+	l.stats.inFlight.Add(int64(len(messages)))
+	defer l.stats.inFlight.Add(-int64(len(messages)))
+	abandoned := new(abandonedHandlers)
+	defer l.waitForAbandoned(abandoned, len(messages))
+	startedAt := time.Now()
+	resolved := make([]types.Message, 0, len(messages))
+	blobKeys := make(map[string]string)
+	unresolved := make(map[string]error)
+	for _, message := range messages {
+		resolvedMessage, blobKey, err := l.resolveMessage(message)
+		if err != nil {
+			unresolved[aws.ToString(message.MessageId)] = err
+			continue
+		}
+		blobKeys[aws.ToString(message.MessageId)] = blobKey
+		resolved = append(resolved, resolvedMessage)
+	}
+	var result BatchResult
+	if len(resolved) > 0 {
+		result = l.handleBatchWithTimeout(withAbandonedHandlers(l.handlersContext(), abandoned), handler, resolved)
+	}
+	for id, err := range unresolved {
+		result.AddFailure(id, err)
+	}
+	l.stats.latencies.record(time.Since(startedAt))
+	for _, stop := range stopHeartbeats {
+		stop()
+	}
+
+	succeeded := make(map[string]bool, len(result.Succeeded))
+	for _, id := range result.Succeeded {
+		succeeded[id] = true
+	}
+
+	for _, message := range messages {
+		id := aws.ToString(message.MessageId)
+		if succeeded[id] {
+			l.stats.succeeded.Add(1)
+			l.ack(message, l.blobDeleter(blobKeys[id]))
+			continue
+		}
+
+		err, ok := result.Failed[id]
+		if !ok || err == nil {
+			err = errors.New(errNoBatchResult)
+		}
+		l.logger.Errorw("message handling failed", "messageId", id, "error", err)
+		l.stats.failed.Add(1)
+		l.handleFailure(message, err)
+	}
+}
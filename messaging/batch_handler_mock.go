@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: messaging/zaws/batch_handler.go
+
+// Package mock_zaws is a generated GoMock package.
+package zaws
+
+import (
+	context "context"
+	reflect "reflect"
+
+	types "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBatchMessageHandler is a mock of BatchMessageHandler interface.
+type MockBatchMessageHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchMessageHandlerMockRecorder
+}
+
+// MockBatchMessageHandlerMockRecorder is the mock recorder for MockBatchMessageHandler.
+type MockBatchMessageHandlerMockRecorder struct {
+	mock *MockBatchMessageHandler
+}
+
+// NewMockBatchMessageHandler creates a new mock instance.
+func NewMockBatchMessageHandler(ctrl *gomock.Controller) *MockBatchMessageHandler {
+	mock := &MockBatchMessageHandler{ctrl: ctrl}
+	mock.recorder = &MockBatchMessageHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchMessageHandler) EXPECT() *MockBatchMessageHandlerMockRecorder {
+	return m.recorder
+}
+
+// HandleBatch mocks base method.
+func (m *MockBatchMessageHandler) HandleBatch(ctx context.Context, messages []types.Message) BatchResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleBatch", ctx, messages)
+	ret0, _ := ret[0].(BatchResult)
+	return ret0
+}
+
+// HandleBatch indicates an expected call of HandleBatch.
+func (mr *MockBatchMessageHandlerMockRecorder) HandleBatch(ctx, messages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleBatch", reflect.TypeOf((*MockBatchMessageHandler)(nil).HandleBatch), ctx, messages)
+}
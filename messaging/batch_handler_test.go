@@ -0,0 +1,115 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBatchHandler struct {
+	*MockMessageHandler
+	*MockBatchMessageHandler
+}
+
+func TestBatchResult(t *testing.T) {
+	t.Run("AddSuccess and AddFailure record the outcome by message ID", func(t *testing.T) {
+		var result BatchResult
+		err := errors.New("test error")
+
+		result.AddSuccess("a")
+		result.AddFailure("b", err)
+
+		assert.Equal(t, []string{"a"}, result.Succeeded)
+		assert.Equal(t, map[string]error{"b": err}, result.Failed)
+	})
+}
+
+func TestSQSListener_handleBatch(t *testing.T) {
+	messages := []types.Message{
+		{MessageId: aws.String("a"), ReceiptHandle: aws.String("handle-a")},
+		{MessageId: aws.String("b"), ReceiptHandle: aws.String("handle-b")},
+		{MessageId: aws.String("c"), ReceiptHandle: aws.String("handle-c")},
+	}
+
+	t.Run("handleBatch deletes only the messages reported as succeeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		handler := NewMockBatchMessageHandler(ctrl)
+		handler.
+			EXPECT().
+			HandleBatch(gomock.Any(), messages).
+			Return(BatchResult{
+				Succeeded: []string{"a"},
+				Failed:    map[string]error{"b": errors.New("test error")},
+			})
+		sqsClient.
+			EXPECT().
+			DeleteMessage(gomock.Any(), &sqs.DeleteMessageInput{
+				QueueUrl:      sut.queueURL,
+				ReceiptHandle: aws.String("handle-a"),
+			}).
+			Return(&sqs.DeleteMessageOutput{}, nil).
+			Times(1)
+
+		sut.handleBatch(handler, messages)
+	})
+}
+
+func TestSQSListener_Run_batchHandler(t *testing.T) {
+	t.Run("Run passes every received message to a batch handler in a single call", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.maxNumberOfMessages = 10
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		batchHandler := NewMockBatchMessageHandler(ctrl)
+		singleHandler := NewMockMessageHandler(ctrl)
+		sut.handler = testBatchHandler{MockMessageHandler: singleHandler, MockBatchMessageHandler: batchHandler}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			Return(&sqs.ReceiveMessageOutput{Messages: messagesWithIDs("a", "b")}, nil)
+		second := sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(blockUntilCancelled)
+		gomock.InOrder(first, second)
+
+		singleHandler.EXPECT().Handle(gomock.Any()).Times(0)
+		batchHandler.
+			EXPECT().
+			HandleBatch(gomock.Any(), messagesWithIDs("a", "b")).
+			DoAndReturn(func(context.Context, []types.Message) BatchResult {
+				cancel()
+				return BatchResult{Succeeded: []string{"a", "b"}}
+			})
+		sqsClient.
+			EXPECT().
+			DeleteMessage(gomock.Any(), gomock.Any()).
+			Return(&sqs.DeleteMessageOutput{}, nil).
+			Times(2)
+
+		err := sut.Run(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func messagesWithIDs(ids ...string) []types.Message {
+	messages := make([]types.Message, 0, len(ids))
+	for _, id := range ids {
+		messages = append(messages, types.Message{MessageId: aws.String(id), ReceiptHandle: aws.String("handle-" + id)})
+	}
+	return messages
+}
@@ -0,0 +1,73 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const errInvalidBlobKey = "invalid blob key"
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps message bodies that are too large to be sent through SNS or SQS, see ClaimCheckConfig.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body []byte) error
+	// Get returns ErrBlobNotFound when there is no blob for key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FileBlobStore keeps blobs as files in a local directory. It is meant for development and tests.
+type FileBlobStore struct {
+	dir string
+}
+
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+func (s *FileBlobStore) Put(_ context.Context, key string, body []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, body, 0o644)
+}
+
+func (s *FileBlobStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	body, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return body, err
+}
+
+func (s *FileBlobStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path keeps blobs inside the directory of the store, whatever the key.
+func (s *FileBlobStore) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || key == "." || key == ".." {
+		return "", errors.New(errInvalidBlobKey)
+	}
+	return filepath.Join(s.dir, key), nil
+}
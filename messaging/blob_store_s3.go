@@ -0,0 +1,78 @@
+package zaws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type s3Client interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// S3BlobStore keeps blobs in an S3 bucket, under an optional key prefix. A lifecycle rule on the bucket should
+// expire blobs that are never deleted, such as the ones published to topics.
+type S3BlobStore struct {
+	s3Client s3Client
+	bucket   string
+	prefix   string
+}
+
+func NewS3BlobStore(region, bucket, prefix string) (*S3BlobStore, error) {
+	cfg, err := awsConfig.LoadDefaultConfig(context.Background(), awsConfig.WithRegion(region))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewS3BlobStoreWithConfig(cfg, bucket, prefix), nil
+}
+
+func NewS3BlobStoreWithConfig(cfg aws.Config, bucket, prefix string) *S3BlobStore {
+	return &S3BlobStore{
+		s3Client: s3.NewFromConfig(cfg),
+		bucket:   bucket,
+		prefix:   prefix,
+	}
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, body []byte) error {
+	_, err := s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+		Body:   bytes.NewReader(body),
+	})
+	return err
+}
+
+func (s *S3BlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	result, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer result.Body.Close()
+
+	return io.ReadAll(result.Body)
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + key),
+	})
+	return err
+}
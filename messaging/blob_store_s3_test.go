@@ -0,0 +1,87 @@
+package zaws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewS3BlobStoreWithConfig(t *testing.T) {
+	t.Run("NewS3BlobStoreWithConfig returns a store for the bucket and prefix", func(t *testing.T) {
+		store := NewS3BlobStoreWithConfig(aws.Config{Region: "ap-south-1"}, "test-bucket", "claims/")
+
+		assert.NotNil(t, store.s3Client)
+		assert.Equal(t, "test-bucket", store.bucket)
+		assert.Equal(t, "claims/", store.prefix)
+	})
+}
+
+func TestS3BlobStore(t *testing.T) {
+	ctx := context.Background()
+	client := NewMocks3Client(gomock.NewController(t))
+	store := &S3BlobStore{s3Client: client, bucket: "test-bucket", prefix: "claims/"}
+
+	t.Run("Put uploads the body under the prefixed key", func(t *testing.T) {
+		client.
+			EXPECT().
+			PutObject(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+				assert.Equal(t, "test-bucket", aws.ToString(input.Bucket))
+				assert.Equal(t, "claims/key", aws.ToString(input.Key))
+				body, _ := io.ReadAll(input.Body)
+				assert.Equal(t, []byte("test body"), body)
+				return &s3.PutObjectOutput{}, nil
+			})
+
+		assert.Nil(t, store.Put(ctx, "key", []byte("test body")))
+	})
+
+	t.Run("Get returns the body of the object", func(t *testing.T) {
+		client.
+			EXPECT().
+			GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String("test-bucket"), Key: aws.String("claims/key")}).
+			Return(&s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte("test body")))}, nil)
+
+		body, err := store.Get(ctx, "key")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("test body"), body)
+	})
+
+	t.Run("Get returns ErrBlobNotFound when the object does not exist", func(t *testing.T) {
+		client.
+			EXPECT().
+			GetObject(ctx, gomock.Any()).
+			Return(nil, &types.NoSuchKey{})
+
+		_, err := store.Get(ctx, "key")
+
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+	})
+
+	t.Run("Get returns other errors", func(t *testing.T) {
+		getErr := errors.New("get error")
+		client.EXPECT().GetObject(ctx, gomock.Any()).Return(nil, getErr)
+
+		_, err := store.Get(ctx, "key")
+
+		assert.Equal(t, getErr, err)
+	})
+
+	t.Run("Delete deletes the object", func(t *testing.T) {
+		client.
+			EXPECT().
+			DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String("test-bucket"), Key: aws.String("claims/key")}).
+			Return(&s3.DeleteObjectOutput{}, nil)
+
+		assert.Nil(t, store.Delete(ctx, "key"))
+	})
+}
@@ -0,0 +1,44 @@
+package zaws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileBlobStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileBlobStore(t.TempDir())
+	assert.Nil(t, err)
+
+	t.Run("Get returns the body stored with Put", func(t *testing.T) {
+		err := store.Put(ctx, "key", []byte("test body"))
+		assert.Nil(t, err)
+
+		body, err := store.Get(ctx, "key")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("test body"), body)
+	})
+
+	t.Run("Get returns ErrBlobNotFound after Delete", func(t *testing.T) {
+		err := store.Put(ctx, "deleted", []byte("test body"))
+		assert.Nil(t, err)
+		err = store.Delete(ctx, "deleted")
+		assert.Nil(t, err)
+
+		_, err = store.Get(ctx, "deleted")
+
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+	})
+
+	t.Run("Delete ignores a missing blob", func(t *testing.T) {
+		assert.Nil(t, store.Delete(ctx, "missing"))
+	})
+
+	t.Run("Put rejects keys outside the directory of the store", func(t *testing.T) {
+		assert.EqualError(t, store.Put(ctx, "../key", []byte("test body")), errInvalidBlobKey)
+		assert.EqualError(t, store.Put(ctx, "", []byte("test body")), errInvalidBlobKey)
+	})
+}
@@ -0,0 +1,120 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultClaimCheckThreshold leaves room for message attributes and the SNS envelope below the 256 KB limit
+	// of SNS and SQS.
+	DefaultClaimCheckThreshold = 240 * 1024
+	// ClaimCheckAttribute marks a message whose body is a claim check. Its value is the key of the blob.
+	ClaimCheckAttribute = "X-Claim-Check"
+)
+
+// ClaimCheckConfig makes publishers store bodies larger than Threshold bytes (DefaultClaimCheckThreshold by
+// default) in Store and publish a small pointer to the blob instead, marked with ClaimCheckAttribute. Consumers
+// configured with the same store replace the pointer by the original body before the handler runs.
+type ClaimCheckConfig struct {
+	Store     BlobStore
+	Threshold int
+}
+
+type claimCheck struct {
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+}
+
+type claimCheckBody struct {
+	ClaimCheck claimCheck `json:"claimCheck"`
+}
+
+// offload stores message in the blob store when it is above the threshold. It returns the body to publish and the
+// attributes marking it as a claim check, which are nil when message was not offloaded.
+func (c ClaimCheckConfig) offload(ctx context.Context, message string) (string, Attributes, error) {
+	threshold := c.Threshold
+	if threshold <= 0 {
+		threshold = DefaultClaimCheckThreshold
+	}
+	if c.Store == nil || len(message) <= threshold {
+		return message, nil, nil
+	}
+
+	pointer := claimCheck{Key: uuid.NewString(), Size: len(message)}
+	err := c.Store.Put(ctx, pointer.Key, []byte(message))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to store message body: %w", err)
+	}
+
+	b, err := json.Marshal(claimCheckBody{ClaimCheck: pointer})
+	if err != nil {
+		return "", nil, err
+	}
+	return string(b), NewAttributes().String(ClaimCheckAttribute, pointer.Key), nil
+}
+
+func parseClaimCheck(body string) (claimCheck, error) {
+	var pointer claimCheckBody
+	err := json.Unmarshal([]byte(body), &pointer)
+	if err != nil {
+		return claimCheck{}, err
+	}
+	if pointer.ClaimCheck.Key == "" {
+		return claimCheck{}, errors.New("claim check without key")
+	}
+	return pointer.ClaimCheck, nil
+}
+
+// resolveClaimCheck returns the body a claim check points to, or body itself when the message is not marked with
+// ClaimCheckAttribute. An invalid claim check or a missing blob fails permanently. The returned key is empty when
+// body is not a claim check.
+func resolveClaimCheck(ctx context.Context, store BlobStore, marked bool, body string) (string, string, error) {
+	if store == nil || !marked {
+		return body, "", nil
+	}
+	pointer, err := parseClaimCheck(body)
+	if err != nil {
+		return "", "", Permanent(fmt.Errorf("invalid claim check: %w", err))
+	}
+
+	blob, err := store.Get(ctx, pointer.Key)
+	if errors.Is(err, ErrBlobNotFound) {
+		return "", "", Permanent(fmt.Errorf("claim check %s: %w", pointer.Key, err))
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("claim check %s: %w", pointer.Key, err)
+	}
+	return string(blob), pointer.Key, nil
+}
+
+// resolveMessage replaces a claim check body of message by the body kept in the blob store of the listener.
+func (l *SQSListener) resolveMessage(message types.Message) (types.Message, string, error) {
+	_, marked := message.MessageAttributes[ClaimCheckAttribute]
+	body, key, err := resolveClaimCheck(context.Background(), l.blobStore, marked, aws.ToString(message.Body))
+	if err != nil || key == "" {
+		return message, "", err
+	}
+	message.Body = aws.String(body)
+	return message, key, nil
+}
+
+// blobDeleter returns the function that deletes the blob of a claim check once its message is deleted from the
+// queue, or nil when there is nothing to delete or DeleteBlobsAfterAck is not set.
+func (l *SQSListener) blobDeleter(key string) func() {
+	if key == "" || !l.deleteBlobsAfterAck {
+		return nil
+	}
+	return func() {
+		err := l.blobStore.Delete(context.Background(), key)
+		if err != nil {
+			l.logger.Warnw("failed to delete claim check blob", "key", key, "error", err)
+		}
+	}
+}
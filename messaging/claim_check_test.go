@@ -0,0 +1,228 @@
+package zaws
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBlobStore(t *testing.T) *FileBlobStore {
+	store, err := NewFileBlobStore(t.TempDir())
+	assert.Nil(t, err)
+	return store
+}
+
+func TestClaimCheckConfig_offload(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("offload keeps messages up to the threshold", func(t *testing.T) {
+		config := ClaimCheckConfig{Store: newTestBlobStore(t), Threshold: 4}
+
+		body, attributes, err := config.offload(ctx, "test")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "test", body)
+		assert.Nil(t, attributes)
+	})
+
+	t.Run("offload keeps messages when no store is configured", func(t *testing.T) {
+		message := strings.Repeat("a", DefaultClaimCheckThreshold+1)
+
+		body, attributes, err := ClaimCheckConfig{}.offload(ctx, message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, message, body)
+		assert.Nil(t, attributes)
+	})
+
+	t.Run("offload stores messages above the threshold and returns a marked pointer to them", func(t *testing.T) {
+		store := newTestBlobStore(t)
+		config := ClaimCheckConfig{Store: store, Threshold: 4}
+
+		body, attributes, err := config.offload(ctx, "test message")
+
+		assert.Nil(t, err)
+		pointer, err := parseClaimCheck(body)
+		assert.Nil(t, err)
+		assert.Equal(t, len("test message"), pointer.Size)
+		key, err := attributes.GetString(ClaimCheckAttribute)
+		assert.Nil(t, err)
+		assert.Equal(t, pointer.Key, key)
+		stored, err := store.Get(ctx, pointer.Key)
+		assert.Nil(t, err)
+		assert.Equal(t, "test message", string(stored))
+	})
+}
+
+func TestResolveClaimCheck(t *testing.T) {
+	ctx := context.Background()
+	store := newTestBlobStore(t)
+
+	t.Run("resolveClaimCheck returns bodies that are not marked as claim checks unchanged", func(t *testing.T) {
+		body, key, err := resolveClaimCheck(ctx, store, false, `{"claimCheck":{"key":"key","size":12}}`)
+
+		assert.Nil(t, err)
+		assert.Equal(t, `{"claimCheck":{"key":"key","size":12}}`, body)
+		assert.Empty(t, key)
+	})
+
+	t.Run("resolveClaimCheck returns the stored body of a claim check", func(t *testing.T) {
+		assert.Nil(t, store.Put(ctx, "key", []byte("test message")))
+
+		body, key, err := resolveClaimCheck(ctx, store, true, `{"claimCheck":{"key":"key","size":12}}`)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "test message", body)
+		assert.Equal(t, "key", key)
+	})
+
+	t.Run("resolveClaimCheck fails permanently when the blob does not exist", func(t *testing.T) {
+		_, _, err := resolveClaimCheck(ctx, store, true, `{"claimCheck":{"key":"missing","size":12}}`)
+
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+		assert.True(t, IsPermanent(err))
+	})
+
+	t.Run("resolveClaimCheck fails permanently when a marked body is not a claim check", func(t *testing.T) {
+		_, _, err := resolveClaimCheck(ctx, store, true, `not a claim check`)
+
+		assert.True(t, IsPermanent(err))
+	})
+}
+
+func TestSQSListener_handleMessage_claimCheck(t *testing.T) {
+	ctx := context.Background()
+	message := types.Message{
+		MessageId:     aws.String("test-id"),
+		Body:          aws.String(`{"claimCheck":{"key":"key","size":12}}`),
+		ReceiptHandle: aws.String("test handle"),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			ClaimCheckAttribute: {DataType: aws.String("String"), StringValue: aws.String("key")},
+		},
+	}
+
+	t.Run("handleMessage passes the stored body to the handler and deletes the blob after the ack", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		store := newTestBlobStore(t)
+		assert.Nil(t, store.Put(ctx, "key", []byte("test message")))
+		sut.blobStore = store
+		sut.deleteBlobsAfterAck = true
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		handler := NewMockMessageHandler(ctrl)
+		sut.handler = handler
+		sut.start(nil)
+		handler.
+			EXPECT().
+			Handle(gomock.Any()).
+			DoAndReturn(func(message types.Message) error {
+				assert.Equal(t, "test message", aws.ToString(message.Body))
+				return nil
+			})
+		sqsClient.
+			EXPECT().
+			DeleteMessage(gomock.Any(), &sqs.DeleteMessageInput{QueueUrl: sut.queueURL, ReceiptHandle: aws.String("test handle")}).
+			Return(&sqs.DeleteMessageOutput{}, nil)
+
+		ok := sut.handleMessage(message)
+
+		assert.True(t, ok)
+		_, err := store.Get(ctx, "key")
+		assert.ErrorIs(t, err, ErrBlobNotFound)
+	})
+
+	t.Run("handleMessage keeps the blob when the handler fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		store := newTestBlobStore(t)
+		assert.Nil(t, store.Put(ctx, "key", []byte("test message")))
+		sut.blobStore = store
+		sut.deleteBlobsAfterAck = true
+		handler := NewMockMessageHandler(ctrl)
+		sut.handler = handler
+		sut.start(nil)
+		handler.EXPECT().Handle(gomock.Any()).Return(assert.AnError)
+
+		ok := sut.handleMessage(message)
+
+		assert.False(t, ok)
+		_, err := store.Get(ctx, "key")
+		assert.Nil(t, err)
+	})
+
+	t.Run("handleMessage keeps the blob when the message cannot be deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		store := newTestBlobStore(t)
+		assert.Nil(t, store.Put(ctx, "key", []byte("test message")))
+		sut.blobStore = store
+		sut.deleteBlobsAfterAck = true
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		handler := NewMockMessageHandler(ctrl)
+		sut.handler = handler
+		sut.start(nil)
+		handler.EXPECT().Handle(gomock.Any()).Return(nil)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(nil, assert.AnError)
+
+		ok := sut.handleMessage(message)
+
+		assert.True(t, ok)
+		_, err := store.Get(ctx, "key")
+		assert.Nil(t, err)
+	})
+}
+
+func TestQueuePublisher_Publish_claimCheck(t *testing.T) {
+	t.Run("Publish sends a claim check instead of a message above the threshold", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		store := newTestBlobStore(t)
+		publisher := &QueuePublisher{sqsClient: sqsClient, queueName: "test-queue", queueURL: "www.test-queue.com"}
+		publisher.SetClaimCheck(ClaimCheckConfig{Store: store, Threshold: 4})
+		sqsClient.
+			EXPECT().
+			SendMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, input *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+				assert.Contains(t, input.MessageAttributes, ClaimCheckAttribute)
+				body, _, err := resolveClaimCheck(ctx, store, true, aws.ToString(input.MessageBody))
+				assert.Nil(t, err)
+				assert.Equal(t, "test message", body)
+				return &sqs.SendMessageOutput{}, nil
+			})
+
+		err := publisher.Publish("test message")
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestMultiTopicHandler_Handle_claimCheck(t *testing.T) {
+	t.Run("Handle passes the stored body of a claim check to the event handler", func(t *testing.T) {
+		store := newTestBlobStore(t)
+		assert.Nil(t, store.Put(context.Background(), "key", []byte("test message")))
+		var received string
+		mtH := NewMultiTopicHandler()
+		mtH.BlobStore = store
+		mtH.RegisterHandler("test-topic", func(message string) error {
+			received = message
+			return nil
+		})
+		message := types.Message{Body: aws.String(`{
+			"subject": "test-topic",
+			"message": "{\"claimCheck\":{\"key\":\"key\",\"size\":12}}",
+			"MessageAttributes": {"X-Claim-Check": {"Type": "String", "Value": "key"}}
+		}`)}
+
+		err := mtH.Handle(message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "test message", received)
+	})
+}
@@ -0,0 +1,71 @@
+package zaws
+
+import (
+	"context"
+
+	"github.com/ammyy9908/go-common-libraries/correlation"
+	"github.com/ammyy9908/go-common-libraries/logger"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+// ContextMessageHandler handles a message with a context that carries the correlation ID of the message and a
+// logger, see LoggerFromContext.
+type ContextMessageHandler interface {
+	Handle(ctx context.Context, message types.Message) error
+}
+
+type ContextMessageHandlerFunc func(ctx context.Context, message types.Message) error
+
+func (f ContextMessageHandlerFunc) Handle(ctx context.Context, message types.Message) error {
+	return f(ctx, message)
+}
+
+// AdaptMessageHandler turns a MessageHandler into a ContextMessageHandler that ignores the context.
+func AdaptMessageHandler(handler MessageHandler) ContextMessageHandler {
+	return ContextMessageHandlerFunc(func(_ context.Context, message types.Message) error {
+		return handler.Handle(message)
+	})
+}
+
+type loggerKey struct{}
+
+// LoggerFromContext returns the logger the listener attached to the context of a handler. It logs the correlation
+// ID of the message. Outside of a handler it returns a no-op logger.
+func LoggerFromContext(ctx context.Context) *zap.SugaredLogger {
+	if log, ok := ctx.Value(loggerKey{}).(*zap.SugaredLogger); ok {
+		return log
+	}
+	return zap.NewNop().Sugar()
+}
+
+// handlerContext returns the context a handler runs with. It carries the X-Correlation-ID attribute of the message,
+// or a new correlation ID when the message has none, and a logger with that correlation ID. It is derived from
+// handlersContext, so in-flight messages can finish after the listener stops until the drain deadline.
+func (l *SQSListener) handlerContext(message types.Message) context.Context {
+	correlationID := correlationIDFromMessage(message)
+	if correlationID == "" {
+		correlationID = correlation.NewId()
+	}
+
+	ctx := correlation.WithId(l.handlersContext(), correlationID)
+	return context.WithValue(ctx, loggerKey{}, logger.AddCorrelation(ctx, l.logger))
+}
+
+// handlersContext is cancelled when handlers have to stop: DrainTimeout after the listener was stopped, when the
+// context of Drain ends or once Run has returned.
+func (l *SQSListener) handlersContext() context.Context {
+	if l.handlersCtx == nil {
+		return context.Background()
+	}
+	return l.handlersCtx
+}
+
+// messageHandler returns the ContextHandler of the listener or its Handler adapted to a ContextMessageHandler.
+func (l *SQSListener) messageHandler() ContextMessageHandler {
+	if l.contextHandler != nil {
+		return l.contextHandler
+	}
+	return AdaptMessageHandler(l.handler)
+}
@@ -0,0 +1,156 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ammyy9908/go-common-libraries/correlation"
+	"github.com/ammyy9908/go-common-libraries/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptMessageHandler(t *testing.T) {
+	t.Run("AdaptMessageHandler passes the message to the MessageHandler and returns its error", func(t *testing.T) {
+		handler := NewMockMessageHandler(gomock.NewController(t))
+		message := types.Message{MessageId: aws.String("test-id")}
+		handlerErr := errors.New("handler error")
+		handler.EXPECT().Handle(message).Return(handlerErr)
+
+		err := AdaptMessageHandler(handler).Handle(context.Background(), message)
+
+		assert.Equal(t, handlerErr, err)
+	})
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	t.Run("LoggerFromContext returns a no-op logger outside of a handler", func(t *testing.T) {
+		assert.NotNil(t, LoggerFromContext(context.Background()))
+	})
+}
+
+func TestSQSListener_handlerContext(t *testing.T) {
+	t.Run("handlerContext carries the correlation ID of the message and a logger", func(t *testing.T) {
+		sut := getTestListener()
+		message := types.Message{MessageAttributes: map[string]types.MessageAttributeValue{
+			logger.CorrelationID: {DataType: aws.String("String"), StringValue: aws.String("test-id")},
+		}}
+
+		ctx := sut.handlerContext(message)
+		correlationID, err := correlation.FromContext(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "test-id", correlationID)
+		assert.NotSame(t, sut.logger, LoggerFromContext(ctx))
+	})
+
+	t.Run("handlerContext generates a correlation ID when the message does not have one", func(t *testing.T) {
+		sut := getTestListener()
+
+		correlationID, err := correlation.FromContext(sut.handlerContext(types.Message{}))
+
+		assert.Nil(t, err)
+		assert.NotEmpty(t, correlationID)
+	})
+}
+
+func TestSQSListener_handleMessage_contextHandler(t *testing.T) {
+	t.Run("handleMessage runs the context handler with the handler context", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		var correlationID string
+		sut.contextHandler = ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+			correlationID, _ = correlation.FromContext(ctx)
+			return nil
+		})
+		sut.start(nil)
+		message := types.Message{
+			ReceiptHandle: aws.String("test handle"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				logger.CorrelationID: {DataType: aws.String("String"), StringValue: aws.String("test-id")},
+			},
+		}
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		ok := sut.handleMessage(message)
+
+		assert.True(t, ok)
+		assert.Equal(t, "test-id", correlationID)
+	})
+}
+
+func TestSQSListener_start_middlewares(t *testing.T) {
+	t.Run("start wraps the handler with the context middlewares inside the middlewares", func(t *testing.T) {
+		var calls []string
+		sut := getTestListener()
+		sut.middlewares = []Middleware{func(next MessageHandler) MessageHandler {
+			return MessageHandlerFunc(func(message types.Message) error {
+				calls = append(calls, "middleware")
+				return next.Handle(message)
+			})
+		}}
+		sut.contextMiddlewares = []ContextMiddleware{func(next ContextMessageHandler) ContextMessageHandler {
+			return ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+				calls = append(calls, "context middleware")
+				return next.Handle(ctx, message)
+			})
+		}}
+		sut.contextHandler = ContextMessageHandlerFunc(func(context.Context, types.Message) error {
+			calls = append(calls, "handler")
+			return nil
+		})
+		sut.start(nil)
+
+		err := sut.chain.Handle(context.Background(), types.Message{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"middleware", "context middleware", "handler"}, calls)
+	})
+}
+
+func TestSQSListener_Run_handlerContext(t *testing.T) {
+	t.Run("Run cancels the handler context once the drain timeout has passed after it was stopped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.gracefulShutdownManager = nil
+		sut.drainTimeout = 20 * time.Millisecond
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		started := make(chan struct{})
+		sut.contextHandler = ContextMessageHandlerFunc(func(ctx context.Context, _ types.Message) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		message := types.Message{MessageId: aws.String("test-id"), ReceiptHandle: aws.String("test handle")}
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{message}}, nil)
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(blockUntilCancelled).
+			AnyTimes()
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error)
+		go func() { runErr <- sut.Run(ctx) }()
+		<-started
+
+		cancel()
+
+		select {
+		case err := <-runErr:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("handler context was not cancelled")
+		}
+		assert.Equal(t, int64(1), sut.Stats().Failed)
+	})
+}
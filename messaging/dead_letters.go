@@ -0,0 +1,347 @@
+package zaws
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/goccy/go-json"
+)
+
+const (
+	DefaultPeekMessages             = 10
+	DefaultRedriveVisibilityTimeout = 5 * time.Minute
+	// peekVisibilityTimeout hides the dead letters that were already looked at until they are released, so that
+	// they are not received twice.
+	peekVisibilityTimeout = 30 * time.Second
+	maxReceiveMessages    = 10
+)
+
+// RedriveConfig controls which dead letters RedriveDeadLetters moves back to the source queue and how.
+type RedriveConfig struct {
+	// Filter selects the messages to redrive. Messages it rejects stay in the dead-letter queue. nil selects every
+	// message.
+	Filter func(message types.Message) bool
+	// RateLimit, when greater than 0, limits how many messages per second are sent to the source queue.
+	RateLimit float64
+	// MaxMessages, when greater than 0, stops the redrive after that many messages.
+	MaxMessages int
+	// DryRun counts the messages that would be redriven without sending or deleting anything.
+	DryRun bool
+	// ArchiveFile, when set, is a file every redriven message is appended to as a JSON line before it is deleted
+	// from the dead-letter queue.
+	ArchiveFile string
+	// VisibilityTimeout hides the received dead letters from other consumers (DefaultRedriveVisibilityTimeout by
+	// default, at least a second). It is extended every half timeout until the redrive is done, so that messages
+	// that are held back or wait for RateLimit are not received again.
+	VisibilityTimeout time.Duration
+}
+
+type RedriveResult struct {
+	// Redriven counts the messages moved to the source queue, or that would have been moved with DryRun.
+	Redriven int
+	// Skipped counts the messages rejected by the filter.
+	Skipped int
+	// Failed counts the messages that could not be sent. They stay in the dead-letter queue.
+	Failed int
+	// NotDeleted counts the redriven messages that were sent but could not be deleted from the dead-letter queue.
+	// They are part of Redriven and reappear in the dead-letter queue after their visibility timeout, so redriving
+	// them again delivers them twice.
+	NotDeleted int
+}
+
+type archivedMessage struct {
+	MessageID         string                                 `json:"messageId"`
+	Body              string                                 `json:"body"`
+	Attributes        map[string]string                      `json:"attributes,omitempty"`
+	MessageAttributes map[string]types.MessageAttributeValue `json:"messageAttributes,omitempty"`
+	ArchivedAt        time.Time                              `json:"archivedAt"`
+}
+
+// PeekDeadLetters returns up to max messages (DefaultPeekMessages by default) of the dead-letter queue of
+// queueName and makes them visible again, so that they stay in the queue. Their receive count increases.
+func (m *Manager) PeekDeadLetters(ctx context.Context, queueName string, max int) ([]types.Message, error) {
+	if max <= 0 {
+		max = DefaultPeekMessages
+	}
+	_, dlqURL, err := m.deadLetterQueue(ctx, queueName)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []types.Message
+	defer func() {
+		m.releaseDeadLetters(dlqURL, messages)
+	}()
+
+	seen := make(map[string]bool)
+	for len(messages) < max {
+		batch, err := m.receiveDeadLetters(ctx, dlqURL, minInt(max-len(messages), maxReceiveMessages), peekVisibilityTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, message := range batch {
+			id := aws.ToString(message.MessageId)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			found = true
+			messages = append(messages, message)
+		}
+		if !found {
+			break
+		}
+	}
+	return messages, nil
+}
+
+// RedriveDeadLetters moves the messages of the dead-letter queue of queueName back to queueName until the
+// dead-letter queue has no message left that the redrive has not seen yet. The failure attributes added by the
+// listener are removed. Messages that are not redriven are made visible again in the dead-letter queue once the
+// redrive is done.
+func (m *Manager) RedriveDeadLetters(ctx context.Context, queueName string, config RedriveConfig) (RedriveResult, error) {
+	var result RedriveResult
+	queueURL, dlqURL, err := m.deadLetterQueue(ctx, queueName)
+	if err != nil {
+		return result, err
+	}
+
+	var archive *json.Encoder
+	if config.ArchiveFile != "" && !config.DryRun {
+		file, err := os.OpenFile(config.ArchiveFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return result, err
+		}
+		defer file.Close()
+		archive = json.NewEncoder(file)
+	}
+
+	visibilityTimeout := config.VisibilityTimeout
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = DefaultRedriveVisibilityTimeout
+	}
+	if visibilityTimeout < time.Second {
+		visibilityTimeout = time.Second
+	}
+	held := &heldDeadLetters{messages: make(map[string]types.Message)}
+	stopHiding := m.keepDeadLettersHidden(ctx, dlqURL, held, visibilityTimeout)
+	defer func() {
+		stopHiding()
+		m.releaseDeadLetters(dlqURL, held.list())
+	}()
+
+	var limiter tokenBucket
+	limiter.setLimit(config.RateLimit, 1)
+	seen := make(map[string]bool)
+	for config.MaxMessages <= 0 || result.Redriven < config.MaxMessages {
+		messages, err := m.receiveDeadLetters(ctx, dlqURL, maxReceiveMessages, visibilityTimeout)
+		if err != nil {
+			return result, err
+		}
+
+		found := false
+		for _, message := range messages {
+			id := aws.ToString(message.MessageId)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			found = true
+			held.add(message)
+
+			switch {
+			case config.MaxMessages > 0 && result.Redriven >= config.MaxMessages:
+				continue
+			case config.Filter != nil && !config.Filter(message):
+				result.Skipped++
+				continue
+			case config.DryRun:
+				result.Redriven++
+				continue
+			}
+
+			_, err = limiter.take(ctx, 1)
+			if err != nil {
+				return result, err
+			}
+			if archive != nil {
+				err = archive.Encode(archivedMessage{
+					MessageID:         id,
+					Body:              aws.ToString(message.Body),
+					Attributes:        message.Attributes,
+					MessageAttributes: message.MessageAttributes,
+					ArchivedAt:        time.Now().UTC(),
+				})
+				if err != nil {
+					return result, err
+				}
+			}
+			sent, err := m.redrive(ctx, queueURL, dlqURL, message)
+			if !sent {
+				result.Failed++
+				continue
+			}
+			held.remove(id)
+			if err != nil {
+				result.NotDeleted++
+			}
+			result.Redriven++
+		}
+		if !found {
+			break
+		}
+	}
+	return result, nil
+}
+
+// redrive sends a dead letter to the source queue and deletes it from the dead-letter queue. sent reports whether
+// the message reached the source queue, also when deleting it failed afterwards.
+func (m *Manager) redrive(ctx context.Context, queueURL, dlqURL string, message types.Message) (sent bool, err error) {
+	attributes := make(map[string]types.MessageAttributeValue, len(message.MessageAttributes))
+	for key, value := range message.MessageAttributes {
+		if key == FailureAttribute {
+			continue
+		}
+		attributes[key] = value
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(queueURL),
+		MessageBody:       message.Body,
+		MessageAttributes: attributes,
+	}
+	if IsFifo(queueURL) {
+		input.MessageGroupId = aws.String(messageGroupID(message))
+		input.MessageDeduplicationId = message.MessageId
+	}
+	_, err = m.sqsClient.SendMessage(ctx, input)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = m.sqsClient.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(dlqURL),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	return true, err
+}
+
+func (m *Manager) receiveDeadLetters(ctx context.Context, dlqURL string, max int, visibilityTimeout time.Duration) ([]types.Message, error) {
+	result, err := m.sqsClient.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(dlqURL),
+		MaxNumberOfMessages:   int32(max),
+		VisibilityTimeout:     int32(visibilityTimeout.Seconds()),
+		MessageAttributeNames: []string{"All"},
+		AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Messages, nil
+}
+
+// heldDeadLetters are the dead letters a redrive received and has not moved yet, by message ID.
+type heldDeadLetters struct {
+	mu       sync.Mutex
+	messages map[string]types.Message
+}
+
+func (h *heldDeadLetters) add(message types.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages[aws.ToString(message.MessageId)] = message
+}
+
+func (h *heldDeadLetters) remove(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.messages, id)
+}
+
+func (h *heldDeadLetters) list() []types.Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	messages := make([]types.Message, 0, len(h.messages))
+	for _, message := range h.messages {
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// keepDeadLettersHidden extends the visibility timeout of the held dead letters every half timeout until the
+// returned stop function is called.
+func (m *Manager) keepDeadLettersHidden(ctx context.Context, dlqURL string, held *heldDeadLetters, timeout time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			for _, message := range held.list() {
+				_, _ = m.sqsClient.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+					QueueUrl:          aws.String(dlqURL),
+					ReceiptHandle:     message.ReceiptHandle,
+					VisibilityTimeout: int32(timeout.Seconds()),
+				})
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// releaseDeadLetters makes received dead letters visible again. Messages that cannot be released reappear once their
+// visibility timeout has passed.
+func (m *Manager) releaseDeadLetters(dlqURL string, messages []types.Message) {
+	ctx := context.Background()
+	for _, message := range messages {
+		_, _ = m.sqsClient.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+			QueueUrl:          aws.String(dlqURL),
+			ReceiptHandle:     message.ReceiptHandle,
+			VisibilityTimeout: 0,
+		})
+	}
+}
+
+// deadLetterQueue returns the URLs of queueName and of its dead-letter queue, read from the RedrivePolicy of the
+// queue or named with the ErrorQueueSuffix convention.
+func (m *Manager) deadLetterQueue(ctx context.Context, queueName string) (string, string, error) {
+	queueURL, err := GetQueueURL(m.sqsClient, queueName)
+	if err != nil {
+		return "", "", err
+	}
+
+	dlqName := deadLetterQueueName(queueName)
+	if name, ok := redrivePolicyTarget(ctx, m.sqsClient, aws.String(queueURL)); ok {
+		dlqName = name
+	}
+	dlqURL, err := GetQueueURL(m.sqsClient, dlqName)
+	if err != nil {
+		return "", "", err
+	}
+	return queueURL, dlqURL, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
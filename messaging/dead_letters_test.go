@@ -0,0 +1,214 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/goccy/go-json"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDeadLetter(id string) types.Message {
+	return types.Message{
+		MessageId:     aws.String(id),
+		Body:          aws.String("body-" + id),
+		ReceiptHandle: aws.String("handle-" + id),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"X-Correlation-ID": {DataType: aws.String("String"), StringValue: aws.String("correlation-" + id)},
+			FailureAttribute:   {DataType: aws.String("String"), StringValue: aws.String(`{"reason":"permanent failure","originalMessageId":"` + id + `"}`)},
+		},
+	}
+}
+
+func newDeadLetterTestManager(t *testing.T) (*Manager, *MockISQSClient) {
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
+	sqsClient.
+		EXPECT().
+		GetQueueUrl(gomock.Any(), &sqs.GetQueueUrlInput{QueueName: aws.String("test-queue")}).
+		Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("test-queue.com")}, nil)
+	sqsClient.
+		EXPECT().
+		GetQueueAttributes(gomock.Any(), gomock.Any()).
+		Return(&sqs.GetQueueAttributesOutput{}, nil)
+	sqsClient.
+		EXPECT().
+		GetQueueUrl(gomock.Any(), &sqs.GetQueueUrlInput{QueueName: aws.String("test-queue" + ErrorQueueSuffix)}).
+		Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("test-queue_ERROR.com")}, nil)
+	return &Manager{sqsClient: sqsClient}, sqsClient
+}
+
+func expectDeadLetters(sqsClient *MockISQSClient, batches ...[]types.Message) {
+	calls := make([]*gomock.Call, 0, len(batches))
+	for _, batch := range batches {
+		calls = append(calls, sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			Return(&sqs.ReceiveMessageOutput{Messages: batch}, nil))
+	}
+	gomock.InOrder(calls...)
+}
+
+func expectReleased(sqsClient *MockISQSClient, ids ...string) {
+	for _, id := range ids {
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:      aws.String("test-queue_ERROR.com"),
+				ReceiptHandle: aws.String("handle-" + id),
+			}).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil)
+	}
+}
+
+func TestManager_PeekDeadLetters(t *testing.T) {
+	t.Run("PeekDeadLetters returns the dead letters once each and makes them visible again", func(t *testing.T) {
+		sut, sqsClient := newDeadLetterTestManager(t)
+		expectDeadLetters(sqsClient,
+			[]types.Message{testDeadLetter("a"), testDeadLetter("b")},
+			[]types.Message{testDeadLetter("a")},
+		)
+		expectReleased(sqsClient, "a", "b")
+
+		messages, err := sut.PeekDeadLetters(context.Background(), "test-queue", 0)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []types.Message{testDeadLetter("a"), testDeadLetter("b")}, messages)
+	})
+
+	t.Run("PeekDeadLetters returns an error when the receive fails", func(t *testing.T) {
+		sut, sqsClient := newDeadLetterTestManager(t)
+		receiveErr := errors.New("receive error")
+		sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).Return(nil, receiveErr)
+
+		_, err := sut.PeekDeadLetters(context.Background(), "test-queue", 5)
+
+		assert.Equal(t, receiveErr, err)
+	})
+}
+
+func TestManager_RedriveDeadLetters(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RedriveDeadLetters sends the selected messages without failure attributes and archives them", func(t *testing.T) {
+		sut, sqsClient := newDeadLetterTestManager(t)
+		archiveFile := filepath.Join(t.TempDir(), "archive.jsonl")
+		expectDeadLetters(sqsClient, []types.Message{testDeadLetter("a"), testDeadLetter("b")}, nil)
+		sqsClient.
+			EXPECT().
+			SendMessage(gomock.Any(), &sqs.SendMessageInput{
+				QueueUrl:    aws.String("test-queue.com"),
+				MessageBody: aws.String("body-a"),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"X-Correlation-ID": {DataType: aws.String("String"), StringValue: aws.String("correlation-a")},
+				},
+			}).
+			Return(&sqs.SendMessageOutput{}, nil)
+		sqsClient.
+			EXPECT().
+			DeleteMessage(gomock.Any(), &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String("test-queue_ERROR.com"),
+				ReceiptHandle: aws.String("handle-a"),
+			}).
+			Return(&sqs.DeleteMessageOutput{}, nil)
+		expectReleased(sqsClient, "b")
+
+		result, err := sut.RedriveDeadLetters(ctx, "test-queue", RedriveConfig{
+			Filter:      func(message types.Message) bool { return aws.ToString(message.MessageId) == "a" },
+			RateLimit:   100,
+			ArchiveFile: archiveFile,
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, RedriveResult{Redriven: 1, Skipped: 1}, result)
+		archived, err := os.ReadFile(archiveFile)
+		assert.Nil(t, err)
+		lines := strings.Split(strings.TrimSpace(string(archived)), "\n")
+		assert.Len(t, lines, 1)
+		var message archivedMessage
+		assert.Nil(t, json.Unmarshal([]byte(lines[0]), &message))
+		assert.Equal(t, "a", message.MessageID)
+		assert.Equal(t, "body-a", message.Body)
+	})
+
+	t.Run("RedriveDeadLetters with DryRun counts the messages without moving them", func(t *testing.T) {
+		sut, sqsClient := newDeadLetterTestManager(t)
+		expectDeadLetters(sqsClient, []types.Message{testDeadLetter("a"), testDeadLetter("b")}, []types.Message{testDeadLetter("a")})
+		sqsClient.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Times(0)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Times(0)
+		expectReleased(sqsClient, "a", "b")
+
+		result, err := sut.RedriveDeadLetters(ctx, "test-queue", RedriveConfig{DryRun: true})
+
+		assert.Nil(t, err)
+		assert.Equal(t, RedriveResult{Redriven: 2}, result)
+	})
+
+	t.Run("RedriveDeadLetters keeps messages that cannot be sent in the dead-letter queue", func(t *testing.T) {
+		sut, sqsClient := newDeadLetterTestManager(t)
+		expectDeadLetters(sqsClient, []types.Message{testDeadLetter("a")}, nil)
+		sqsClient.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Return(nil, errors.New("send error"))
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Times(0)
+		expectReleased(sqsClient, "a")
+
+		result, err := sut.RedriveDeadLetters(ctx, "test-queue", RedriveConfig{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, RedriveResult{Failed: 1}, result)
+	})
+
+	t.Run("RedriveDeadLetters counts sent messages that cannot be deleted as redriven and not deleted", func(t *testing.T) {
+		sut, sqsClient := newDeadLetterTestManager(t)
+		expectDeadLetters(sqsClient, []types.Message{testDeadLetter("a")}, nil)
+		sqsClient.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Return(&sqs.SendMessageOutput{}, nil)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(nil, errors.New("delete error"))
+		sqsClient.EXPECT().ChangeMessageVisibility(gomock.Any(), gomock.Any()).Times(0)
+
+		result, err := sut.RedriveDeadLetters(ctx, "test-queue", RedriveConfig{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, RedriveResult{Redriven: 1, NotDeleted: 1}, result)
+	})
+
+	t.Run("RedriveDeadLetters keeps the messages waiting for the rate limit hidden", func(t *testing.T) {
+		sut, sqsClient := newDeadLetterTestManager(t)
+		expectDeadLetters(sqsClient, []types.Message{testDeadLetter("a"), testDeadLetter("b")}, nil)
+		sqsClient.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Return(&sqs.SendMessageOutput{}, nil).Times(2)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil).Times(2)
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          aws.String("test-queue_ERROR.com"),
+				ReceiptHandle:     aws.String("handle-b"),
+				VisibilityTimeout: 1,
+			}).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil).
+			MinTimes(1)
+
+		result, err := sut.RedriveDeadLetters(ctx, "test-queue", RedriveConfig{RateLimit: 1.5, VisibilityTimeout: time.Second})
+
+		assert.Nil(t, err)
+		assert.Equal(t, RedriveResult{Redriven: 2}, result)
+	})
+
+	t.Run("RedriveDeadLetters stops after MaxMessages", func(t *testing.T) {
+		sut, sqsClient := newDeadLetterTestManager(t)
+		expectDeadLetters(sqsClient, []types.Message{testDeadLetter("a"), testDeadLetter("b")})
+		sqsClient.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Return(&sqs.SendMessageOutput{}, nil).Times(1)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil).Times(1)
+		expectReleased(sqsClient, "b")
+
+		result, err := sut.RedriveDeadLetters(ctx, "test-queue", RedriveConfig{MaxMessages: 1})
+
+		assert.Nil(t, err)
+		assert.Equal(t, RedriveResult{Redriven: 1}, result)
+	})
+}
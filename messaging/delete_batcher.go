@@ -0,0 +1,187 @@
+package zaws
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+const (
+	MaxDeleteBatchSize         = 10
+	DefaultDeleteFlushInterval = 1 * time.Second
+	deleteBatchAttempts        = 3
+	deleteBatchRetryWait       = 100 * time.Millisecond
+)
+
+// deleteBatcher buffers receipt handles of successfully handled messages and deletes them with
+// DeleteMessageBatch once the batch is full or the flush interval has passed.
+type deleteBatcher struct {
+	sqsClient ISQSClient
+	queueURL  *string
+	logger    *zap.SugaredLogger
+	size      int
+	interval  time.Duration
+	deleted   *atomic.Int64
+	messages  chan pendingDelete
+	flushes   chan chan struct{}
+	done      chan struct{}
+}
+
+func newDeleteBatcher(sqsClient ISQSClient, queueURL *string, logger *zap.SugaredLogger, size int, interval time.Duration, deleted *atomic.Int64) *deleteBatcher {
+	if size > MaxDeleteBatchSize {
+		size = MaxDeleteBatchSize
+	}
+	if interval <= 0 {
+		interval = DefaultDeleteFlushInterval
+	}
+
+	b := &deleteBatcher{
+		sqsClient: sqsClient,
+		queueURL:  queueURL,
+		logger:    logger,
+		size:      size,
+		interval:  interval,
+		deleted:   deleted,
+		messages:  make(chan pendingDelete, size),
+		flushes:   make(chan chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// pendingDelete is a message waiting to be deleted. onDeleted, when set, is called once the delete succeeded.
+type pendingDelete struct {
+	message   types.Message
+	onDeleted func()
+}
+
+func (b *deleteBatcher) add(message types.Message, onDeleted func()) {
+	b.messages <- pendingDelete{message: message, onDeleted: onDeleted}
+}
+
+// close flushes every pending delete and stops the batcher. No messages may be added afterwards.
+func (b *deleteBatcher) close() {
+	close(b.messages)
+	<-b.done
+}
+
+// flushPending deletes the messages added so far without stopping the batcher.
+func (b *deleteBatcher) flushPending() {
+	flushed := make(chan struct{})
+	select {
+	case b.flushes <- flushed:
+	case <-b.done:
+		return
+	}
+	select {
+	case <-flushed:
+	case <-b.done:
+	}
+}
+
+func (b *deleteBatcher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	pending := make([]pendingDelete, 0, b.size)
+	for {
+		select {
+		case message, ok := <-b.messages:
+			if !ok {
+				b.flush(pending)
+				return
+			}
+			pending = append(pending, message)
+			if len(pending) >= b.size {
+				b.flush(pending)
+				pending = pending[:0]
+			}
+		case <-ticker.C:
+			b.flush(pending)
+			pending = pending[:0]
+		case flushed := <-b.flushes:
+			for len(b.messages) > 0 {
+				pending = append(pending, <-b.messages)
+				if len(pending) >= b.size {
+					b.flush(pending)
+					pending = pending[:0]
+				}
+			}
+			b.flush(pending)
+			pending = pending[:0]
+			close(flushed)
+		}
+	}
+}
+
+func (b *deleteBatcher) flush(messages []pendingDelete) {
+	ctx := context.Background()
+	entries := make([]types.DeleteMessageBatchRequestEntry, 0, len(messages))
+	for i, message := range messages {
+		entries = append(entries, types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(strconv.Itoa(i)),
+			ReceiptHandle: message.message.ReceiptHandle,
+		})
+	}
+
+	for attempt := 1; len(entries) > 0; attempt++ {
+		result, err := b.sqsClient.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+			QueueUrl: b.queueURL,
+			Entries:  entries,
+		})
+		if err != nil {
+			if attempt >= deleteBatchAttempts {
+				b.logger.Errorw("failed to delete message batch", "entries", len(entries), "error", err)
+				return
+			}
+			time.Sleep(deleteBatchRetryWait * time.Duration(attempt))
+			continue
+		}
+
+		b.deleted.Add(int64(len(result.Successful)))
+		for _, success := range result.Successful {
+			index, err := strconv.Atoi(aws.ToString(success.Id))
+			if err == nil && index < len(messages) && messages[index].onDeleted != nil {
+				messages[index].onDeleted()
+			}
+		}
+		entries = b.retryableEntries(entries, result.Failed, messages, attempt >= deleteBatchAttempts)
+	}
+}
+
+// retryableEntries logs every failed entry and returns the ones that are worth sending again.
+func (b *deleteBatcher) retryableEntries(entries []types.DeleteMessageBatchRequestEntry, failed []types.BatchResultErrorEntry, messages []pendingDelete, lastAttempt bool) []types.DeleteMessageBatchRequestEntry {
+	byID := make(map[string]types.DeleteMessageBatchRequestEntry, len(entries))
+	for _, entry := range entries {
+		byID[*entry.Id] = entry
+	}
+
+	var retry []types.DeleteMessageBatchRequestEntry
+	for _, failure := range failed {
+		entry, ok := byID[aws.ToString(failure.Id)]
+		if !ok {
+			continue
+		}
+		index, _ := strconv.Atoi(*entry.Id)
+		fields := []interface{}{
+			"messageId", aws.ToString(messages[index].message.MessageId),
+			"code", aws.ToString(failure.Code),
+			"reason", aws.ToString(failure.Message),
+		}
+		if failure.SenderFault || lastAttempt {
+			b.logger.Errorw("failed to delete message", fields...)
+			continue
+		}
+		b.logger.Warnw("failed to delete message, retrying", fields...)
+		retry = append(retry, entry)
+	}
+	return retry
+}
@@ -0,0 +1,165 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func testDeleteMessage(id string) types.Message {
+	return types.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("handle-" + id),
+	}
+}
+
+func TestDeleteBatcher(t *testing.T) {
+	queueURL := aws.String("test-queue.com")
+	log := zap.NewNop().Sugar()
+
+	t.Run("deleteBatcher deletes messages in one call once the batch is full", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), &sqs.DeleteMessageBatchInput{
+				QueueUrl: queueURL,
+				Entries: []types.DeleteMessageBatchRequestEntry{
+					{Id: aws.String("0"), ReceiptHandle: aws.String("handle-a")},
+					{Id: aws.String("1"), ReceiptHandle: aws.String("handle-b")},
+				},
+			}).
+			Return(&sqs.DeleteMessageBatchOutput{Successful: []types.DeleteMessageBatchResultEntry{{Id: aws.String("0")}, {Id: aws.String("1")}}}, nil)
+		deleted := new(atomic.Int64)
+		sut := newDeleteBatcher(sqsClient, queueURL, log, 2, time.Hour, deleted)
+
+		sut.add(testDeleteMessage("a"), nil)
+		sut.add(testDeleteMessage("b"), nil)
+		sut.close()
+
+		assert.Equal(t, int64(2), deleted.Load())
+	})
+
+	t.Run("flushPending deletes the added messages without waiting for the interval", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+				assert.Len(t, input.Entries, 2)
+				return &sqs.DeleteMessageBatchOutput{Successful: []types.DeleteMessageBatchResultEntry{{Id: aws.String("0")}, {Id: aws.String("1")}}}, nil
+			})
+		deleted := new(atomic.Int64)
+		sut := newDeleteBatcher(sqsClient, queueURL, log, 10, time.Hour, deleted)
+		defer sut.close()
+
+		sut.add(testDeleteMessage("a"), nil)
+		sut.add(testDeleteMessage("b"), nil)
+		sut.flushPending()
+
+		assert.Equal(t, int64(2), deleted.Load())
+	})
+
+	t.Run("deleteBatcher flushes pending deletes when the interval passes", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		flushed := make(chan struct{})
+		sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+				assert.Len(t, input.Entries, 1)
+				close(flushed)
+				return &sqs.DeleteMessageBatchOutput{}, nil
+			})
+		sut := newDeleteBatcher(sqsClient, queueURL, log, 10, 20*time.Millisecond, new(atomic.Int64))
+		defer sut.close()
+
+		sut.add(testDeleteMessage("a"), nil)
+
+		select {
+		case <-flushed:
+		case <-time.After(time.Second):
+			t.Fatal("pending delete was not flushed")
+		}
+	})
+
+	t.Run("deleteBatcher flushes pending deletes when it is closed", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), gomock.Any()).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil)
+		sut := newDeleteBatcher(sqsClient, queueURL, log, 10, time.Hour, new(atomic.Int64))
+
+		sut.add(testDeleteMessage("a"), nil)
+		sut.close()
+	})
+
+	t.Run("deleteBatcher retries only failed entries that were not caused by the sender", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		first := sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), gomock.Any()).
+			Return(&sqs.DeleteMessageBatchOutput{
+				Failed: []types.BatchResultErrorEntry{
+					{Id: aws.String("1"), Code: aws.String("InternalError"), SenderFault: false},
+					{Id: aws.String("2"), Code: aws.String("ReceiptHandleIsInvalid"), SenderFault: true},
+				},
+			}, nil)
+		second := sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), &sqs.DeleteMessageBatchInput{
+				QueueUrl: queueURL,
+				Entries: []types.DeleteMessageBatchRequestEntry{
+					{Id: aws.String("1"), ReceiptHandle: aws.String("handle-b")},
+				},
+			}).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil)
+		gomock.InOrder(first, second)
+		sut := newDeleteBatcher(sqsClient, queueURL, log, 3, time.Hour, new(atomic.Int64))
+
+		sut.add(testDeleteMessage("a"), nil)
+		sut.add(testDeleteMessage("b"), nil)
+		sut.add(testDeleteMessage("c"), nil)
+		sut.close()
+	})
+
+	t.Run("deleteBatcher gives up after the maximum number of attempts", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("network error")).
+			Times(deleteBatchAttempts)
+		sut := newDeleteBatcher(sqsClient, queueURL, log, 10, time.Hour, new(atomic.Int64))
+
+		sut.add(testDeleteMessage("a"), nil)
+		sut.close()
+	})
+	t.Run("deleteBatcher calls onDeleted only for the messages that were deleted", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), gomock.Any()).
+			Return(&sqs.DeleteMessageBatchOutput{
+				Successful: []types.DeleteMessageBatchResultEntry{{Id: aws.String("0")}},
+				Failed:     []types.BatchResultErrorEntry{{Id: aws.String("1"), Code: aws.String("ReceiptHandleIsInvalid"), SenderFault: true}},
+			}, nil)
+		sut := newDeleteBatcher(sqsClient, queueURL, log, 10, time.Hour, new(atomic.Int64))
+		var deleted []string
+
+		sut.add(testDeleteMessage("a"), func() { deleted = append(deleted, "a") })
+		sut.add(testDeleteMessage("b"), func() { deleted = append(deleted, "b") })
+		sut.close()
+
+		assert.Equal(t, []string{"a"}, deleted)
+	})
+}
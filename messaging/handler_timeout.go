@@ -0,0 +1,116 @@
+package zaws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/goccy/go-json"
+)
+
+// handleBatchWithTimeout runs a batch handler with the HandlerTimeout of the listener. Every message of a batch
+// that does not finish in time fails with ErrHandlerTimeout, even when the handler keeps running. Such a handler is
+// added to the abandonedHandlers of ctx.
+func (l *SQSListener) handleBatchWithTimeout(ctx context.Context, handler BatchMessageHandler, messages []types.Message) BatchResult {
+	if l.handlerTimeout <= 0 {
+		return handler.HandleBatch(ctx, messages)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.handlerTimeout)
+	defer cancel()
+	done := make(chan BatchResult, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		done <- handler.HandleBatch(ctx, messages)
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+	}
+
+	abandon(ctx, finished)
+	err := fmt.Errorf("%w after %s", ErrHandlerTimeout, l.handlerTimeout)
+	var result BatchResult
+	for _, message := range messages {
+		l.logSlowHandler(message)
+		result.AddFailure(aws.ToString(message.MessageId), err)
+	}
+	return result
+}
+
+type abandonedKey struct{}
+
+// abandonedHandlers collects the handlers that a timeout stopped waiting for. The listener waits for them before
+// it frees the worker, so that handlers ignoring their context still count against MaxConcurrency, but not past
+// the drain deadline.
+type abandonedHandlers struct {
+	mu       sync.Mutex
+	finished []<-chan struct{}
+}
+
+func withAbandonedHandlers(ctx context.Context, abandoned *abandonedHandlers) context.Context {
+	return context.WithValue(ctx, abandonedKey{}, abandoned)
+}
+
+// abandon records a handler that closes finished once it returns, when ctx carries abandonedHandlers.
+func abandon(ctx context.Context, finished <-chan struct{}) {
+	abandoned, ok := ctx.Value(abandonedKey{}).(*abandonedHandlers)
+	if !ok {
+		return
+	}
+	abandoned.mu.Lock()
+	defer abandoned.mu.Unlock()
+	abandoned.finished = append(abandoned.finished, finished)
+}
+
+// wait blocks until every abandoned handler has returned or ctx ends. It returns false when ctx ended first.
+func (a *abandonedHandlers) wait(ctx context.Context) bool {
+	a.mu.Lock()
+	finished := a.finished
+	a.mu.Unlock()
+	for _, f := range finished {
+		select {
+		case <-f:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// waitForAbandoned keeps the worker of messages busy until the handlers abandoned by a timeout return. At the drain
+// deadline it stops waiting and counts the messages as abandoned.
+func (l *SQSListener) waitForAbandoned(abandoned *abandonedHandlers, messages int) {
+	if abandoned.wait(l.handlersContext()) {
+		return
+	}
+	l.stats.abandoned.Add(int64(messages))
+	l.logger.Warnw("stopped waiting for timed out handlers at the drain deadline",
+		"queue", l.queueName,
+		"messages", messages,
+	)
+}
+
+func (l *SQSListener) logSlowHandler(message types.Message) {
+	l.logger.Warnw("message handler timed out",
+		"queue", l.queueName,
+		"messageId", aws.ToString(message.MessageId),
+		"subject", messageSubject(message),
+		"timeout", l.handlerTimeout,
+	)
+}
+
+// messageSubject returns the subject of an SNS notification or an Event, or an empty string for other messages.
+func messageSubject(message types.Message) string {
+	var event Event
+	err := json.Unmarshal([]byte(aws.ToString(message.Body)), &event)
+	if err != nil {
+		return ""
+	}
+	return event.Subject
+}
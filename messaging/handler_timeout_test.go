@@ -0,0 +1,163 @@
+package zaws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSQSListener_handleMessage_handlerTimeout(t *testing.T) {
+	message := types.Message{
+		MessageId:     aws.String("test-id"),
+		Body:          aws.String(`{"Subject":"test-topic","Message":"test message"}`),
+		ReceiptHandle: aws.String("test handle"),
+	}
+
+	t.Run("handleMessage fails a message whose handler ignores the deadline, logs it and waits for the handler", func(t *testing.T) {
+		sut := getTestListener()
+		core, logs := observer.New(zap.WarnLevel)
+		sut.logger = zap.New(core).Sugar()
+		sut.handlerTimeout = 20 * time.Millisecond
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		release := make(chan struct{})
+		sut.contextHandler = ContextMessageHandlerFunc(func(context.Context, types.Message) error {
+			<-release
+			return nil
+		})
+		sut.start(nil)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Times(0)
+		returned := make(chan bool)
+
+		go func() { returned <- sut.handleMessage(message) }()
+
+		assert.Eventually(t, func() bool { return sut.Stats().Failed == 1 }, time.Second, 5*time.Millisecond)
+		select {
+		case <-returned:
+			t.Fatal("handleMessage returned before the abandoned handler")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(release)
+		assert.False(t, <-returned)
+		slowLogs := logs.FilterMessage("message handler timed out").All()
+		assert.Len(t, slowLogs, 1)
+		assert.Equal(t, "test-id", slowLogs[0].ContextMap()["messageId"])
+		assert.Equal(t, "test-topic", slowLogs[0].ContextMap()["subject"])
+	})
+
+	t.Run("handleMessage passes the deadline to the handler context", func(t *testing.T) {
+		sut := getTestListener()
+		sut.handlerTimeout = time.Second
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		var hasDeadline bool
+		sut.contextHandler = ContextMessageHandlerFunc(func(ctx context.Context, _ types.Message) error {
+			_, hasDeadline = ctx.Deadline()
+			return nil
+		})
+		sut.start(nil)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil)
+
+		ok := sut.handleMessage(message)
+
+		assert.True(t, ok)
+		assert.True(t, hasDeadline)
+	})
+}
+
+func TestSQSListener_handleBatch_handlerTimeout(t *testing.T) {
+	t.Run("handleBatch fails every message of a batch that does not finish in time and waits for the handler", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.handlerTimeout = 20 * time.Millisecond
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		handler := NewMockBatchMessageHandler(ctrl)
+		release := make(chan struct{})
+		messages := []types.Message{
+			{MessageId: aws.String("a"), ReceiptHandle: aws.String("handle-a")},
+			{MessageId: aws.String("b"), ReceiptHandle: aws.String("handle-b")},
+		}
+		handler.
+			EXPECT().
+			HandleBatch(gomock.Any(), messages).
+			DoAndReturn(func(context.Context, []types.Message) BatchResult {
+				<-release
+				return BatchResult{Succeeded: []string{"a", "b"}}
+			})
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		returned := make(chan struct{})
+
+		go func() {
+			sut.handleBatch(handler, messages)
+			close(returned)
+		}()
+
+		assert.Eventually(t, func() bool { return sut.Stats().Failed == 2 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, int64(2), sut.Stats().InFlight)
+		close(release)
+		<-returned
+		assert.Zero(t, sut.Stats().InFlight)
+	})
+}
+
+func TestMessageSubject(t *testing.T) {
+	assert.Equal(t, "test-topic", messageSubject(types.Message{Body: aws.String(`{"Subject":"test-topic","Message":"test"}`)}))
+	assert.Empty(t, messageSubject(types.Message{Body: aws.String("not json")}))
+}
+
+func TestSQSListener_Run_handlerTimeout(t *testing.T) {
+	t.Run("Run does not wait past the drain timeout for a timed out handler that ignores its context", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.handlerTimeout = 20 * time.Millisecond
+		sut.drainTimeout = 50 * time.Millisecond
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		release := make(chan struct{})
+		defer close(release)
+		sut.contextHandler = ContextMessageHandlerFunc(func(context.Context, types.Message) error {
+			cancel()
+			<-release
+			return nil
+		})
+		message := types.Message{MessageId: aws.String("test-id"), ReceiptHandle: aws.String("test handle")}
+
+		gomock.InOrder(
+			sqsClient.
+				EXPECT().
+				ReceiveMessage(gomock.Any(), gomock.Any()).
+				Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{message}}, nil),
+			sqsClient.
+				EXPECT().
+				ReceiveMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(blockUntilCancelled).
+				AnyTimes(),
+		)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Times(0)
+		returned := make(chan error, 1)
+
+		go func() { returned <- sut.Run(ctx) }()
+
+		select {
+		case err := <-returned:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("Run did not return after the drain timeout")
+		}
+		assert.Equal(t, int64(1), sut.Stats().Abandoned)
+		assert.Zero(t, sut.Stats().InFlight)
+		sut.gracefulShutdownManager.ShutdownWaitGroup.Wait()
+	})
+}
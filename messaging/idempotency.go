@@ -0,0 +1,158 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultIdempotencyTTL   = 24 * time.Hour
+	DefaultIdempotencyLease = 5 * time.Minute
+	errMissingMessageID     = "message has no message id"
+	errMissingKeyAttribute  = "message has no idempotency key attribute"
+	errMissingKeyField      = "message body has no idempotency key field"
+)
+
+var (
+	ErrDuplicateInProgress = errors.New("a duplicate of the message is being processed")
+	ErrLeaseLost           = errors.New("idempotency lease was taken over by another consumer")
+)
+
+type IdempotencyStatus int
+
+const (
+	// IdempotencyAcquired means the caller holds the processing lease of the key.
+	IdempotencyAcquired IdempotencyStatus = iota
+	// IdempotencyInProgress means another consumer holds an unexpired processing lease of the key.
+	IdempotencyInProgress
+	// IdempotencyCompleted means the key was processed successfully within its TTL.
+	IdempotencyCompleted
+)
+
+// IdempotencyStore records which messages were processed. Implementations must make Acquire atomic so that only
+// one of several concurrent duplicates gets IdempotencyAcquired. Every Acquire passes a unique token that is stored
+// with the lease, so that a consumer whose lease expired and was taken over cannot complete or release the lease of
+// the consumer that took it over.
+type IdempotencyStore interface {
+	// Acquire takes a processing lease on key for token unless it is completed or leased by someone else. An expired
+	// lease can be taken over.
+	Acquire(ctx context.Context, key, token string, lease time.Duration) (IdempotencyStatus, error)
+	// Complete marks key as processed and remembers it for ttl. It returns ErrLeaseLost when the lease is held with
+	// another token.
+	Complete(ctx context.Context, key, token string, ttl time.Duration) error
+	// Release drops the processing lease on key so that the message can be processed again. It does nothing when
+	// the lease is held with another token.
+	Release(ctx context.Context, key, token string) error
+}
+
+// IdempotencyKeyFunc returns the key that identifies duplicates of a message.
+type IdempotencyKeyFunc func(message types.Message) (string, error)
+
+type IdempotencyConfig struct {
+	Store IdempotencyStore
+	// Key defaults to MessageIDKey.
+	Key IdempotencyKeyFunc
+	// TTL is how long a processed key is remembered (DefaultIdempotencyTTL by default). Lease is how long a key stays
+	// claimed while its handler runs (DefaultIdempotencyLease by default) and should exceed the handler duration.
+	TTL   time.Duration
+	Lease time.Duration
+}
+
+// MessageIDKey uses the SQS message ID, which detects redeliveries of the same message but not messages that
+// were published twice.
+func MessageIDKey(message types.Message) (string, error) {
+	if aws.ToString(message.MessageId) == "" {
+		return "", errors.New(errMissingMessageID)
+	}
+	return aws.ToString(message.MessageId), nil
+}
+
+// AttributeKey uses the string value of the message attribute name.
+func AttributeKey(name string) IdempotencyKeyFunc {
+	return func(message types.Message) (string, error) {
+		value := aws.ToString(message.MessageAttributes[name].StringValue)
+		if value == "" {
+			return "", fmt.Errorf("%s: %s", errMissingKeyAttribute, name)
+		}
+		return value, nil
+	}
+}
+
+// BodyKey uses a top-level field of the JSON message body.
+func BodyKey(field string) IdempotencyKeyFunc {
+	return func(message types.Message) (string, error) {
+		var body map[string]interface{}
+		err := json.Unmarshal([]byte(aws.ToString(message.Body)), &body)
+		if err != nil {
+			return "", err
+		}
+		value, ok := body[field]
+		if !ok || value == nil {
+			return "", fmt.Errorf("%s: %s", errMissingKeyField, field)
+		}
+		return fmt.Sprint(value), nil
+	}
+}
+
+// Idempotent skips messages whose key was already processed. A message without a key fails permanently. While
+// a duplicate is being processed elsewhere the message is retried after the lease, so that it still runs if the
+// other consumer fails. The lease is released when the handler fails.
+func Idempotent(config IdempotencyConfig) ContextMiddleware {
+	keyFunc := config.Key
+	if keyFunc == nil {
+		keyFunc = MessageIDKey
+	}
+	ttl := config.TTL
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	lease := config.Lease
+	if lease <= 0 {
+		lease = DefaultIdempotencyLease
+	}
+
+	return func(next ContextMessageHandler) ContextMessageHandler {
+		return ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+			key, err := keyFunc(message)
+			if err != nil {
+				return Permanent(fmt.Errorf("idempotency key: %w", err))
+			}
+
+			token := uuid.NewString()
+			status, err := config.Store.Acquire(ctx, key, token, lease)
+			if err != nil {
+				return fmt.Errorf("idempotency store: %w", err)
+			}
+			log := LoggerFromContext(ctx)
+			switch status {
+			case IdempotencyCompleted:
+				log.Infow("skipped duplicate message", "messageId", aws.ToString(message.MessageId), "key", key)
+				return nil
+			case IdempotencyInProgress:
+				return &RetryAfterError{Delay: lease, Err: ErrDuplicateInProgress}
+			}
+
+			err = next.Handle(ctx, message)
+			if err != nil {
+				releaseErr := config.Store.Release(context.Background(), key, token)
+				if releaseErr != nil {
+					log.Warnw("failed to release idempotency lease", "key", key, "error", releaseErr)
+				}
+				return err
+			}
+
+			err = config.Store.Complete(context.Background(), key, token, ttl)
+			if err != nil {
+				log.Errorw("failed to mark message as processed", "key", key, "error", err)
+			}
+			return nil
+		})
+	}
+}
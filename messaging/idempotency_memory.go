@@ -0,0 +1,107 @@
+package zaws
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultMemoryIdempotencyCapacity = 10000
+
+// MemoryIdempotencyStore keeps keys in memory, evicting the least recently used key once capacity is reached.
+// It only detects duplicates received by the same process.
+type MemoryIdempotencyStore struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	key       string
+	token     string
+	completed bool
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore returns a store holding at most capacity keys (DefaultMemoryIdempotencyCapacity when
+// capacity is 0 or less).
+func NewMemoryIdempotencyStore(capacity int) *MemoryIdempotencyStore {
+	if capacity <= 0 {
+		capacity = DefaultMemoryIdempotencyCapacity
+	}
+	return &MemoryIdempotencyStore{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryIdempotencyStore) Acquire(_ context.Context, key, token string, lease time.Duration) (IdempotencyStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	if element, ok := s.entries[key]; ok {
+		entry := element.Value.(*memoryIdempotencyEntry)
+		if now.Before(entry.expiresAt) {
+			s.order.MoveToFront(element)
+			if entry.completed {
+				return IdempotencyCompleted, nil
+			}
+			return IdempotencyInProgress, nil
+		}
+		s.remove(element)
+	}
+
+	s.entries[key] = s.order.PushFront(&memoryIdempotencyEntry{key: key, token: token, expiresAt: now.Add(lease)})
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return IdempotencyAcquired, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key, token string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		element = s.order.PushFront(&memoryIdempotencyEntry{key: key, token: token})
+		s.entries[key] = element
+	}
+	entry := element.Value.(*memoryIdempotencyEntry)
+	if entry.token != token {
+		return ErrLeaseLost
+	}
+	entry.completed = true
+	entry.expiresAt = s.now().Add(ttl)
+	s.order.MoveToFront(element)
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*memoryIdempotencyEntry)
+	if !entry.completed && entry.token == token {
+		s.remove(element)
+	}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*memoryIdempotencyEntry).key)
+}
@@ -0,0 +1,88 @@
+package zaws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Acquire reports a leased key as in progress and a completed key as completed", func(t *testing.T) {
+		sut := NewMemoryIdempotencyStore(10)
+
+		acquired, _ := sut.Acquire(ctx, "key", "token", time.Minute)
+		inProgress, _ := sut.Acquire(ctx, "key", "token", time.Minute)
+		_ = sut.Complete(ctx, "key", "token", time.Hour)
+		completed, _ := sut.Acquire(ctx, "key", "token", time.Minute)
+
+		assert.Equal(t, IdempotencyAcquired, acquired)
+		assert.Equal(t, IdempotencyInProgress, inProgress)
+		assert.Equal(t, IdempotencyCompleted, completed)
+	})
+
+	t.Run("Acquire takes over an expired lease", func(t *testing.T) {
+		now := time.Now()
+		sut := NewMemoryIdempotencyStore(10)
+		sut.now = func() time.Time { return now }
+		_, _ = sut.Acquire(ctx, "key", "first", time.Minute)
+
+		now = now.Add(2 * time.Minute)
+		got, _ := sut.Acquire(ctx, "key", "second", time.Minute)
+
+		assert.Equal(t, IdempotencyAcquired, got)
+	})
+
+	t.Run("a lease that was taken over cannot be released or completed with the token of the expired lease", func(t *testing.T) {
+		now := time.Now()
+		sut := NewMemoryIdempotencyStore(10)
+		sut.now = func() time.Time { return now }
+		_, _ = sut.Acquire(ctx, "key", "first", time.Minute)
+		now = now.Add(2 * time.Minute)
+		takenOver, _ := sut.Acquire(ctx, "key", "second", time.Minute)
+
+		_ = sut.Release(ctx, "key", "first")
+		inProgress, _ := sut.Acquire(ctx, "key", "third", time.Minute)
+		completeErr := sut.Complete(ctx, "key", "first", time.Hour)
+		stillInProgress, _ := sut.Acquire(ctx, "key", "third", time.Minute)
+
+		assert.Equal(t, IdempotencyAcquired, takenOver)
+		assert.Equal(t, IdempotencyInProgress, inProgress)
+		assert.ErrorIs(t, completeErr, ErrLeaseLost)
+		assert.Equal(t, IdempotencyInProgress, stillInProgress)
+		assert.Nil(t, sut.Complete(ctx, "key", "second", time.Hour))
+	})
+
+	t.Run("Release makes a leased key available again but keeps completed keys", func(t *testing.T) {
+		sut := NewMemoryIdempotencyStore(10)
+		_, _ = sut.Acquire(ctx, "leased", "token", time.Minute)
+		_ = sut.Complete(ctx, "completed", "token", time.Hour)
+
+		_ = sut.Release(ctx, "leased", "token")
+		_ = sut.Release(ctx, "completed", "token")
+		leased, _ := sut.Acquire(ctx, "leased", "token", time.Minute)
+		completed, _ := sut.Acquire(ctx, "completed", "token", time.Minute)
+
+		assert.Equal(t, IdempotencyAcquired, leased)
+		assert.Equal(t, IdempotencyCompleted, completed)
+	})
+
+	t.Run("Acquire evicts the least recently used key once the capacity is reached", func(t *testing.T) {
+		sut := NewMemoryIdempotencyStore(2)
+		for i := 0; i < 3; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			_, _ = sut.Acquire(ctx, key, "token", time.Minute)
+			_ = sut.Complete(ctx, key, "token", time.Hour)
+		}
+
+		evicted, _ := sut.Acquire(ctx, "key-0", "token", time.Minute)
+		kept, _ := sut.Acquire(ctx, "key-2", "token", time.Minute)
+
+		assert.Equal(t, IdempotencyAcquired, evicted)
+		assert.Equal(t, IdempotencyCompleted, kept)
+	})
+}
@@ -0,0 +1,109 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dbMongo "github.com/ammyy9908/go-common-libraries/db/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	idempotencyStatusProcessing = "processing"
+	idempotencyStatusCompleted  = "completed"
+)
+
+type mongoIdempotencyCollection interface {
+	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult
+	DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+}
+
+// MongoIdempotencyStore keeps one document per key, with the key as _id so that concurrent inserts of the same
+// key fail. A TTL index on expiresAt removes expired keys.
+type MongoIdempotencyStore struct {
+	collection mongoIdempotencyCollection
+	now        func() time.Time
+}
+
+type mongoIdempotencyRecord struct {
+	Key       string    `bson:"_id"`
+	Status    string    `bson:"status"`
+	Token     string    `bson:"token"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// NewMongoIdempotencyStore uses a collection of a client created with the db/mongo package and makes sure the TTL
+// index exists.
+func NewMongoIdempotencyStore(client *mongo.Client, database, collection string) (*MongoIdempotencyStore, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbMongo.TimeoutDurationInSeconds*time.Second)
+	defer cancel()
+
+	coll := client.Database(database).Collection(collection)
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoIdempotencyStore{
+		collection: coll,
+		now:        time.Now,
+	}, nil
+}
+
+// Acquire upserts a processing lease on key when the key is missing or expired. Expired documents can outlive
+// their expiresAt until the TTL monitor runs, so they are taken over here. An unexpired key makes the upsert fail
+// with a duplicate key error and its status is read instead.
+func (s *MongoIdempotencyStore) Acquire(ctx context.Context, key, token string, lease time.Duration) (IdempotencyStatus, error) {
+	now := s.now()
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": key, "expiresAt": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"status": idempotencyStatusProcessing, "token": token, "expiresAt": now.Add(lease)}},
+		options.Update().SetUpsert(true),
+	)
+	if err == nil {
+		return IdempotencyAcquired, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return IdempotencyInProgress, err
+	}
+
+	var record mongoIdempotencyRecord
+	err = s.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return IdempotencyInProgress, nil
+	}
+	if err != nil {
+		return IdempotencyInProgress, err
+	}
+	if record.Status == idempotencyStatusCompleted {
+		return IdempotencyCompleted, nil
+	}
+	return IdempotencyInProgress, nil
+}
+
+// Complete upserts the completed status on the document of key and token. When the lease was taken over, the
+// document has another token and the upsert fails with a duplicate key error.
+func (s *MongoIdempotencyStore) Complete(ctx context.Context, key, token string, ttl time.Duration) error {
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": key, "token": token},
+		bson.M{"$set": bson.M{"status": idempotencyStatusCompleted, "expiresAt": s.now().Add(ttl)}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrLeaseLost
+	}
+	return err
+}
+
+func (s *MongoIdempotencyStore) Release(ctx context.Context, key, token string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": key, "status": idempotencyStatusProcessing, "token": token})
+	return err
+}
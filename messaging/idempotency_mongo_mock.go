@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: messaging/zaws/idempotency_mongo.go
+
+// Package mock_zaws is a generated GoMock package.
+package zaws
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	mongo "go.mongodb.org/mongo-driver/mongo"
+	options "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MockmongoIdempotencyCollection is a mock of mongoIdempotencyCollection interface.
+type MockmongoIdempotencyCollection struct {
+	ctrl     *gomock.Controller
+	recorder *MockmongoIdempotencyCollectionMockRecorder
+}
+
+// MockmongoIdempotencyCollectionMockRecorder is the mock recorder for MockmongoIdempotencyCollection.
+type MockmongoIdempotencyCollectionMockRecorder struct {
+	mock *MockmongoIdempotencyCollection
+}
+
+// NewMockmongoIdempotencyCollection creates a new mock instance.
+func NewMockmongoIdempotencyCollection(ctrl *gomock.Controller) *MockmongoIdempotencyCollection {
+	mock := &MockmongoIdempotencyCollection{ctrl: ctrl}
+	mock.recorder = &MockmongoIdempotencyCollectionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockmongoIdempotencyCollection) EXPECT() *MockmongoIdempotencyCollectionMockRecorder {
+	return m.recorder
+}
+
+// DeleteOne mocks base method.
+func (m *MockmongoIdempotencyCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, filter}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteOne", varargs...)
+	ret0, _ := ret[0].(*mongo.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOne indicates an expected call of DeleteOne.
+func (mr *MockmongoIdempotencyCollectionMockRecorder) DeleteOne(ctx, filter interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, filter}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOne", reflect.TypeOf((*MockmongoIdempotencyCollection)(nil).DeleteOne), varargs...)
+}
+
+// FindOne mocks base method.
+func (m *MockmongoIdempotencyCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, filter}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "FindOne", varargs...)
+	ret0, _ := ret[0].(*mongo.SingleResult)
+	return ret0
+}
+
+// FindOne indicates an expected call of FindOne.
+func (mr *MockmongoIdempotencyCollectionMockRecorder) FindOne(ctx, filter interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, filter}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOne", reflect.TypeOf((*MockmongoIdempotencyCollection)(nil).FindOne), varargs...)
+}
+
+// UpdateOne mocks base method.
+func (m *MockmongoIdempotencyCollection) UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, filter, update}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateOne", varargs...)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOne indicates an expected call of UpdateOne.
+func (mr *MockmongoIdempotencyCollectionMockRecorder) UpdateOne(ctx, filter, update interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, filter, update}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOne", reflect.TypeOf((*MockmongoIdempotencyCollection)(nil).UpdateOne), varargs...)
+}
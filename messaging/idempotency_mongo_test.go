@@ -0,0 +1,120 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func getTestMongoIdempotencyStore(t *testing.T) (*MongoIdempotencyStore, *MockmongoIdempotencyCollection, time.Time) {
+	collection := NewMockmongoIdempotencyCollection(gomock.NewController(t))
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &MongoIdempotencyStore{collection: collection, now: func() time.Time { return now }}, collection, now
+}
+
+func duplicateKeyError() error {
+	return mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+}
+
+func TestMongoIdempotencyStore_Acquire(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Acquire upserts a processing lease when the key is missing or expired", func(t *testing.T) {
+		sut, collection, now := getTestMongoIdempotencyStore(t)
+		collection.
+			EXPECT().
+			UpdateOne(ctx,
+				bson.M{"_id": "key", "expiresAt": bson.M{"$lte": now}},
+				bson.M{"$set": bson.M{"status": idempotencyStatusProcessing, "token": "token", "expiresAt": now.Add(time.Minute)}},
+				gomock.Any(),
+			).
+			Return(&mongo.UpdateResult{UpsertedCount: 1}, nil)
+
+		got, err := sut.Acquire(ctx, "key", "token", time.Minute)
+
+		assert.Nil(t, err)
+		assert.Equal(t, IdempotencyAcquired, got)
+	})
+
+	t.Run("Acquire reads the status of an unexpired key", func(t *testing.T) {
+		sut, collection, now := getTestMongoIdempotencyStore(t)
+		collection.EXPECT().UpdateOne(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, duplicateKeyError()).Times(2)
+		gomock.InOrder(
+			collection.
+				EXPECT().
+				FindOne(ctx, bson.M{"_id": "key"}).
+				Return(mongo.NewSingleResultFromDocument(mongoIdempotencyRecord{Key: "key", Status: idempotencyStatusCompleted, ExpiresAt: now}, nil, nil)),
+			collection.
+				EXPECT().
+				FindOne(ctx, bson.M{"_id": "key"}).
+				Return(mongo.NewSingleResultFromDocument(mongoIdempotencyRecord{Key: "key", Status: idempotencyStatusProcessing, ExpiresAt: now}, nil, nil)),
+		)
+
+		completed, err := sut.Acquire(ctx, "key", "token", time.Minute)
+		assert.Nil(t, err)
+		inProgress, err := sut.Acquire(ctx, "key", "token", time.Minute)
+		assert.Nil(t, err)
+
+		assert.Equal(t, IdempotencyCompleted, completed)
+		assert.Equal(t, IdempotencyInProgress, inProgress)
+	})
+
+	t.Run("Acquire returns other errors of the upsert", func(t *testing.T) {
+		sut, collection, _ := getTestMongoIdempotencyStore(t)
+		collection.EXPECT().UpdateOne(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("connection error"))
+
+		_, err := sut.Acquire(ctx, "key", "token", time.Minute)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestMongoIdempotencyStore_Complete(t *testing.T) {
+	t.Run("Complete marks the key as completed until the TTL", func(t *testing.T) {
+		ctx := context.Background()
+		sut, collection, now := getTestMongoIdempotencyStore(t)
+		collection.
+			EXPECT().
+			UpdateOne(ctx,
+				bson.M{"_id": "key", "token": "token"},
+				bson.M{"$set": bson.M{"status": idempotencyStatusCompleted, "expiresAt": now.Add(time.Hour)}},
+				gomock.Any(),
+			).
+			Return(&mongo.UpdateResult{}, nil)
+
+		err := sut.Complete(ctx, "key", "token", time.Hour)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("Complete returns ErrLeaseLost when the key is leased with another token", func(t *testing.T) {
+		ctx := context.Background()
+		sut, collection, _ := getTestMongoIdempotencyStore(t)
+		collection.EXPECT().UpdateOne(ctx, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, duplicateKeyError())
+
+		err := sut.Complete(ctx, "key", "token", time.Hour)
+
+		assert.ErrorIs(t, err, ErrLeaseLost)
+	})
+}
+
+func TestMongoIdempotencyStore_Release(t *testing.T) {
+	t.Run("Release deletes the key only while it is processing with the same token", func(t *testing.T) {
+		ctx := context.Background()
+		sut, collection, _ := getTestMongoIdempotencyStore(t)
+		collection.
+			EXPECT().
+			DeleteOne(ctx, bson.M{"_id": "key", "status": idempotencyStatusProcessing, "token": "token"}).
+			Return(&mongo.DeleteResult{}, nil)
+
+		err := sut.Release(ctx, "key", "token")
+
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,140 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	message := types.Message{
+		MessageId: aws.String("test-id"),
+		Body:      aws.String(`{"paymentId":"payment-1","amount":10}`),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"X-Idempotency-Key": {DataType: aws.String("String"), StringValue: aws.String("attribute-key")},
+		},
+	}
+
+	t.Run("MessageIDKey returns the message id", func(t *testing.T) {
+		got, err := MessageIDKey(message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "test-id", got)
+	})
+
+	t.Run("AttributeKey returns the value of the attribute", func(t *testing.T) {
+		got, err := AttributeKey("X-Idempotency-Key")(message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "attribute-key", got)
+	})
+
+	t.Run("AttributeKey returns an error when the attribute is missing", func(t *testing.T) {
+		_, err := AttributeKey("missing")(message)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("BodyKey returns a field of the JSON body", func(t *testing.T) {
+		got, err := BodyKey("paymentId")(message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "payment-1", got)
+	})
+
+	t.Run("BodyKey returns an error when the field is missing", func(t *testing.T) {
+		_, err := BodyKey("missing")(message)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestIdempotent(t *testing.T) {
+	message := types.Message{MessageId: aws.String("test-id")}
+
+	t.Run("Idempotent handles a message once and skips its duplicates", func(t *testing.T) {
+		calls := 0
+		handler := ContextMessageHandlerFunc(func(context.Context, types.Message) error {
+			calls++
+			return nil
+		})
+		sut := Idempotent(IdempotencyConfig{Store: NewMemoryIdempotencyStore(10)})(handler)
+
+		first := sut.Handle(context.Background(), message)
+		second := sut.Handle(context.Background(), message)
+
+		assert.Nil(t, first)
+		assert.Nil(t, second)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Idempotent releases the key when the handler fails so that the message is handled again", func(t *testing.T) {
+		calls := 0
+		handler := ContextMessageHandlerFunc(func(context.Context, types.Message) error {
+			calls++
+			if calls == 1 {
+				return errors.New("handler error")
+			}
+			return nil
+		})
+		sut := Idempotent(IdempotencyConfig{Store: NewMemoryIdempotencyStore(10)})(handler)
+
+		first := sut.Handle(context.Background(), message)
+		second := sut.Handle(context.Background(), message)
+
+		assert.NotNil(t, first)
+		assert.Nil(t, second)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Idempotent retries a message after the lease while a duplicate is being processed", func(t *testing.T) {
+		store := NewMemoryIdempotencyStore(10)
+		_, _ = store.Acquire(context.Background(), "test-id", "other", time.Minute)
+		handler := ContextMessageHandlerFunc(func(context.Context, types.Message) error {
+			t.Fatal("duplicate was handled")
+			return nil
+		})
+
+		err := Idempotent(IdempotencyConfig{Store: store, Lease: time.Minute})(handler).Handle(context.Background(), message)
+
+		var retryAfter *RetryAfterError
+		assert.ErrorAs(t, err, &retryAfter)
+		assert.Equal(t, time.Minute, retryAfter.Delay)
+		assert.ErrorIs(t, err, ErrDuplicateInProgress)
+	})
+
+	t.Run("Idempotent does not release a lease that another consumer took over while the handler ran", func(t *testing.T) {
+		now := time.Now()
+		store := NewMemoryIdempotencyStore(10)
+		store.now = func() time.Time { return now }
+		calls := 0
+		handler := ContextMessageHandlerFunc(func(context.Context, types.Message) error {
+			calls++
+			now = now.Add(2 * time.Minute)
+			status, _ := store.Acquire(context.Background(), "test-id", "other", time.Minute)
+			assert.Equal(t, IdempotencyAcquired, status)
+			return errors.New("handler error")
+		})
+		sut := Idempotent(IdempotencyConfig{Store: store, Lease: time.Minute})(handler)
+
+		first := sut.Handle(context.Background(), message)
+		duplicate := sut.Handle(context.Background(), message)
+
+		assert.NotNil(t, first)
+		assert.ErrorIs(t, duplicate, ErrDuplicateInProgress)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Idempotent fails permanently when the message has no key", func(t *testing.T) {
+		handler := ContextMessageHandlerFunc(func(context.Context, types.Message) error { return nil })
+
+		err := Idempotent(IdempotencyConfig{Store: NewMemoryIdempotencyStore(10)})(handler).Handle(context.Background(), types.Message{})
+
+		assert.True(t, IsPermanent(err))
+	})
+}
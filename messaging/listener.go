@@ -2,6 +2,11 @@ package zaws
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ammyy9908/go-common-libraries/gracefulshutdown"
 
@@ -12,27 +17,134 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	DefaultMaxConcurrency = 10
+	DefaultDrainTimeout   = 30 * time.Second
+)
+
+var ErrShutdownRequested = errors.New("graceful shutdown requested")
+
 type ISQSListener interface {
-	Listen()
+	Listen(ctx context.Context)
+	Run(ctx context.Context) error
+	Pause()
+	Resume()
+	Drain(ctx context.Context) (int, error)
 }
 
 type SQSListener struct {
-	queueName                 string
-	queueURL                  *string
-	sqsClient                 ISQSClient
-	logger                    *zap.SugaredLogger
-	handler                   MessageHandler
-	gracefulShutdownManager   *gracefulshutdown.Manager
-	receiveMessageWaitSeconds int
-	maxNumberOfMessages       int
+	queueName                   string
+	queueURL                    *string
+	sqsClient                   ISQSClient
+	logger                      *zap.SugaredLogger
+	handler                     MessageHandler
+	contextHandler              ContextMessageHandler
+	middlewares                 []Middleware
+	contextMiddlewares          []ContextMiddleware
+	gracefulShutdownManager     *gracefulshutdown.Manager
+	receiveMessageWaitSeconds   int
+	maxNumberOfMessages         int
+	maxConcurrency              int
+	drainTimeout                time.Duration
+	handlerTimeout              time.Duration
+	visibilityHeartbeatInterval time.Duration
+	visibilityExtension         time.Duration
+	maxVisibilityExtension      time.Duration
+	deleteBatchSize             int
+	deleteFlushInterval         time.Duration
+	receiveBackoffBase          time.Duration
+	receiveBackoffMax           time.Duration
+	circuitBreakerThreshold     int
+	onHealthChange              HealthChangeFunc
+	redeliveryPolicy            RedeliveryPolicy
+	blobStore                   BlobStore
+	deleteBlobsAfterAck         bool
+	statsLogInterval            time.Duration
+	limiter                     tokenBucket
+	minPollers                  int
+	maxPollers                  int
+	pollerScaleInterval         time.Duration
+	stats                       listenerCounters
+	control                     listenerControl
+	receiveFailures             atomic.Int64
+	circuitOpen                 atomic.Bool
+	dlqMu                       sync.Mutex
+	dlqURL                      string
+	chain                       ContextMessageHandler
+	handlersCtx                 context.Context
+	cancelHandlers              context.CancelFunc
+	workers                     *workerPool
+	deletes                     *deleteBatcher
 }
 
 type ListenerConfig struct {
-	Logger                    *zap.SugaredLogger
-	Handler                   MessageHandler
+	Logger  *zap.SugaredLogger
+	Handler MessageHandler
+	// ContextHandler is used instead of Handler when set. Its context carries the correlation ID of the message and
+	// a logger, see LoggerFromContext.
+	ContextHandler ContextMessageHandler
+	// Middlewares wrap the handler in the given order, see Chain. ContextMiddlewares wrap it inside Middlewares, see
+	// ChainContext. Neither is applied to a BatchMessageHandler.
+	Middlewares        []Middleware
+	ContextMiddlewares []ContextMiddleware
+	// GracefulShutdownManager is optional. When set, closing its ShutdownChannel stops the listener
+	// and Shutdown waits for the listener to finish its in-flight messages.
 	GracefulShutdownManager   *gracefulshutdown.Manager
 	ReceiveMessageWaitSeconds int
 	MaxNumberOfMessages       int
+	// MaxConcurrency limits how many messages are handled at the same time. The listener stops receiving
+	// while every worker is busy. Defaults to DefaultMaxConcurrency.
+	MaxConcurrency int
+	// DrainTimeout is how long in-flight handlers may keep running after the context of Run ended or a graceful
+	// shutdown was requested before their context is cancelled (DefaultDrainTimeout by default).
+	DrainTimeout time.Duration
+	// HandlerTimeout, when set, is the deadline of the handler context of every message, or of every batch for a
+	// BatchMessageHandler. A message whose handler has not returned by then fails with ErrHandlerTimeout and is not
+	// deleted. A handler that ignores the context keeps its worker busy until it returns or the drain deadline
+	// passes, after which it is counted in ListenerStats.Abandoned.
+	HandlerTimeout time.Duration
+	// VisibilityHeartbeatInterval enables extending the visibility timeout of messages while their handler runs.
+	// Every interval the timeout is set to VisibilityExtension (twice the interval by default) until the handler
+	// finishes, its context is cancelled at the drain deadline or MaxVisibilityExtension
+	// (DefaultMaxVisibilityExtension by default) has passed.
+	VisibilityHeartbeatInterval time.Duration
+	VisibilityExtension         time.Duration
+	MaxVisibilityExtension      time.Duration
+	// DeleteBatchSize enables deleting handled messages with DeleteMessageBatch when greater than 1 (at most
+	// MaxDeleteBatchSize). Pending deletes are flushed when the batch is full, every DeleteFlushInterval
+	// (DefaultDeleteFlushInterval by default) and when the listener stops.
+	DeleteBatchSize     int
+	DeleteFlushInterval time.Duration
+	// ReceiveBackoffBase and ReceiveBackoffMax bound the exponential backoff with jitter between failed receives
+	// (DefaultReceiveBackoffBase and DefaultReceiveBackoffMax by default). The backoff resets after a successful receive.
+	ReceiveBackoffBase time.Duration
+	ReceiveBackoffMax  time.Duration
+	// CircuitBreakerThreshold opens the receive circuit after that many consecutive failed receives, which marks the
+	// listener unhealthy and calls OnHealthChange. The circuit closes on the next successful receive. 0 disables it.
+	CircuitBreakerThreshold int
+	OnHealthChange          HealthChangeFunc
+	// RedeliveryPolicy, when set, decides how long a message whose handler failed stays invisible based on its
+	// ApproximateReceiveCount. Handlers can also return RetryAfter to pick the delay themselves.
+	RedeliveryPolicy RedeliveryPolicy
+	// BlobStore resolves claim check bodies, see ClaimCheckConfig. With DeleteBlobsAfterAck the blob is deleted once
+	// the message is deleted from the queue. Do not use it for messages published to topics with more than one
+	// subscriber.
+	BlobStore           BlobStore
+	DeleteBlobsAfterAck bool
+	// RateLimit, when greater than 0, limits how many messages per second the listener receives. It stops receiving
+	// while the limit is reached instead of holding on to messages. RateLimitBurst defaults to RateLimit rounded up.
+	// The limit can be changed with SetRateLimit.
+	RateLimit      float64
+	RateLimitBurst int
+	// MinPollers and MaxPollers bound how many goroutines receive messages at the same time (1 by default). When
+	// MaxPollers is higher, ApproximateNumberOfMessages is read every PollerScaleInterval (DefaultPollerScaleInterval
+	// by default) and a poller is added while the backlog exceeds one receive per poller or removed when the queue
+	// is empty.
+	MinPollers          int
+	MaxPollers          int
+	PollerScaleInterval time.Duration
+	// StatsLogInterval, when set, logs the Stats of the listener at info level every interval while it runs.
+	StatsLogInterval time.Duration
 }
 
 func NewListener(queueName, region string, listenerConfig ListenerConfig) (*SQSListener, error) {
@@ -57,66 +169,330 @@ func NewListenerWithConfig(queueName string, cfg aws.Config, listenerConfig List
 		return nil, err
 	}
 
-	return &SQSListener{
-		queueName:                 queueName,
-		queueURL:                  &queueURL,
-		sqsClient:                 sqsClient,
-		logger:                    listenerConfig.Logger,
-		handler:                   listenerConfig.Handler,
-		gracefulShutdownManager:   listenerConfig.GracefulShutdownManager,
-		receiveMessageWaitSeconds: listenerConfig.ReceiveMessageWaitSeconds,
-		maxNumberOfMessages:       listenerConfig.MaxNumberOfMessages,
-	}, nil
+	listener := &SQSListener{
+		queueName:                   queueName,
+		queueURL:                    &queueURL,
+		sqsClient:                   sqsClient,
+		logger:                      listenerConfig.Logger,
+		handler:                     listenerConfig.Handler,
+		contextHandler:              listenerConfig.ContextHandler,
+		middlewares:                 listenerConfig.Middlewares,
+		contextMiddlewares:          listenerConfig.ContextMiddlewares,
+		gracefulShutdownManager:     listenerConfig.GracefulShutdownManager,
+		receiveMessageWaitSeconds:   listenerConfig.ReceiveMessageWaitSeconds,
+		maxNumberOfMessages:         listenerConfig.MaxNumberOfMessages,
+		maxConcurrency:              listenerConfig.MaxConcurrency,
+		drainTimeout:                listenerConfig.DrainTimeout,
+		handlerTimeout:              listenerConfig.HandlerTimeout,
+		visibilityHeartbeatInterval: listenerConfig.VisibilityHeartbeatInterval,
+		visibilityExtension:         listenerConfig.VisibilityExtension,
+		maxVisibilityExtension:      listenerConfig.MaxVisibilityExtension,
+		deleteBatchSize:             listenerConfig.DeleteBatchSize,
+		deleteFlushInterval:         listenerConfig.DeleteFlushInterval,
+		receiveBackoffBase:          listenerConfig.ReceiveBackoffBase,
+		receiveBackoffMax:           listenerConfig.ReceiveBackoffMax,
+		circuitBreakerThreshold:     listenerConfig.CircuitBreakerThreshold,
+		onHealthChange:              listenerConfig.OnHealthChange,
+		redeliveryPolicy:            listenerConfig.RedeliveryPolicy,
+		blobStore:                   listenerConfig.BlobStore,
+		deleteBlobsAfterAck:         listenerConfig.DeleteBlobsAfterAck,
+		statsLogInterval:            listenerConfig.StatsLogInterval,
+		minPollers:                  listenerConfig.MinPollers,
+		maxPollers:                  listenerConfig.MaxPollers,
+		pollerScaleInterval:         listenerConfig.PollerScaleInterval,
+	}
+	listener.limiter.setLimit(listenerConfig.RateLimit, listenerConfig.RateLimitBurst)
+
+	return listener, nil
+}
+
+// Listen runs the listener until ctx is cancelled or a graceful shutdown is requested and logs why it stopped.
+func (l *SQSListener) Listen(ctx context.Context) {
+	err := l.Run(ctx)
+	l.logger.Info(err.Error())
 }
 
-func (l *SQSListener) Listen() {
-	retrievedMessagesRequest := &sqs.ReceiveMessageInput{
+// Run polls the queue until ctx is cancelled, a graceful shutdown is requested or Drain is called. It cancels the
+// receive in progress, waits for in-flight messages to be handled and returns an error wrapping the reason it stopped.
+// Handlers still running DrainTimeout after ctx ended see their context cancelled.
+func (l *SQSListener) Run(ctx context.Context) error {
+	if l.gracefulShutdownManager != nil {
+		l.gracefulShutdownManager.ShutdownWaitGroup.Add(1)
+		defer l.gracefulShutdownManager.ShutdownWaitGroup.Done()
+	}
+
+	ctx, stopReason := withShutdown(ctx, l.gracefulShutdownManager)
+
+	if l.statsLogInterval > 0 {
+		go l.logStats(ctx, l.statsLogInterval)
+	}
+
+	workers := newWorkerPool(l.concurrency())
+	l.start(workers)
+	finish := l.control.begin()
+	stopped := make(chan struct{})
+	go cancelAfterDrainTimeout(ctx, l.drainTimeout, stopped, l.cancelHandlers)
+	l.runPollers(ctx, l.receiveRequest())
+	workers.stop()
+	close(stopped)
+	l.stop()
+	drained := l.control.isDraining()
+	finish()
+
+	err := stopReason()
+	if err == nil && drained {
+		err = ErrDrained
+	}
+	return fmt.Errorf("listener for queue %s stopped: %w", l.queueName, err)
+}
+
+// start prepares the listener to handle messages on workers.
+func (l *SQSListener) start(workers *workerPool) {
+	if l.deleteBatchSize > 1 {
+		l.deletes = newDeleteBatcher(l.sqsClient, l.queueURL, l.logger, l.deleteBatchSize, l.deleteFlushInterval, &l.stats.deleted)
+	}
+	l.chain = ChainContext(l.messageHandler(), l.contextMiddlewares...)
+	for i := len(l.middlewares) - 1; i >= 0; i-- {
+		l.chain = AdaptMiddleware(l.middlewares[i])(l.chain)
+	}
+	if l.handlerTimeout > 0 {
+		l.chain = ContextTimeout(l.handlerTimeout)(l.chain)
+	}
+	l.handlersCtx, l.cancelHandlers = context.WithCancel(context.Background())
+	l.workers = workers
+}
+
+// stop flushes pending deletes once the workers have finished.
+func (l *SQSListener) stop() {
+	l.cancelHandlers()
+	if l.deletes != nil {
+		l.deletes.close()
+	}
+}
+
+// cancelAfterDrainTimeout calls cancel once timeout (DefaultDrainTimeout when not set) has passed after ctx ended,
+// unless stopped is closed first.
+func cancelAfterDrainTimeout(ctx context.Context, timeout time.Duration, stopped <-chan struct{}, cancel func()) {
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+	select {
+	case <-ctx.Done():
+	case <-stopped:
+		return
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		cancel()
+	case <-stopped:
+	}
+}
+
+func (l *SQSListener) receiveRequest() *sqs.ReceiveMessageInput {
+	return &sqs.ReceiveMessageInput{
 		QueueUrl:              l.queueURL,
 		WaitTimeSeconds:       int32(l.receiveMessageWaitSeconds),
 		MaxNumberOfMessages:   int32(l.maxNumberOfMessages),
 		MessageAttributeNames: []string{"All"},
+		AttributeNames:        []types.QueueAttributeName{types.QueueAttributeNameAll},
+	}
+}
+
+// withShutdown returns a context that is also cancelled when the graceful shutdown manager signals shutdown,
+// together with a function reporting why that context ended.
+func withShutdown(parent context.Context, gracefulShutdownManager *gracefulshutdown.Manager) (context.Context, func() error) {
+	ctx, cancel := context.WithCancel(parent)
+	if gracefulShutdownManager == nil {
+		return ctx, func() error {
+			defer cancel()
+			return parent.Err()
+		}
 	}
 
-	l.poll(retrievedMessagesRequest)
+	shutdownRequested := make(chan struct{})
+	go func() {
+		select {
+		case <-gracefulShutdownManager.ShutdownChannel:
+			close(shutdownRequested)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() error {
+		defer cancel()
+		select {
+		case <-shutdownRequested:
+			return ErrShutdownRequested
+		default:
+			return parent.Err()
+		}
+	}
 }
 
-func (l *SQSListener) poll(request *sqs.ReceiveMessageInput) {
-	ctx := context.Background()
+// poll receives messages until ctx ends, stop is closed or the listener is drained. It waits while the listener
+// is paused.
+func (l *SQSListener) poll(ctx context.Context, request *sqs.ReceiveMessageInput, stop <-chan struct{}) {
 	for {
 		select {
-		case <-l.gracefulShutdownManager.ShutdownChannel:
-			l.gracefulShutdownManager.ShutdownWaitGroup.Wait()
+		case <-stop:
 			return
 		default:
-			retrieveMessageResponse, err := l.sqsClient.ReceiveMessage(ctx, request)
-			if err != nil {
-				l.logger.Error(err.Error())
-				continue
+		}
+		if !l.control.awaitResume(ctx, stop) {
+			return
+		}
+
+		reserved, err := l.workers.reserve(ctx, l.batchSize())
+		if err != nil {
+			return
+		}
+		tokens, err := l.limiter.take(ctx, reserved)
+		if err != nil {
+			l.workers.release(reserved)
+			return
+		}
+		l.workers.release(reserved - tokens)
+		reserved = tokens
+
+		receiveRequest := *request
+		receiveRequest.MaxNumberOfMessages = int32(reserved)
+		receiveCtx, receiveDone := l.control.receiveContext(ctx)
+		retrieveMessageResponse, err := l.sqsClient.ReceiveMessage(receiveCtx, &receiveRequest)
+		interrupted := receiveDone()
+		if err != nil {
+			l.workers.release(reserved)
+			l.limiter.refund(reserved)
+			if ctx.Err() != nil {
+				return
 			}
-			if len(retrieveMessageResponse.Messages) < 1 {
+			if interrupted {
 				continue
 			}
-			for _, m := range retrieveMessageResponse.Messages {
-				l.gracefulShutdownManager.ShutdownWaitGroup.Add(1)
-				go func(message types.Message) {
-					defer l.gracefulShutdownManager.ShutdownWaitGroup.Done()
-					l.handleMessage(message)
-				}(m)
+			if !l.receiveFailed(ctx, err) {
+				return
 			}
+			continue
 		}
+		l.receiveSucceeded()
+		l.limiter.refund(reserved - len(retrieveMessageResponse.Messages))
+
+		l.dispatch(retrieveMessageResponse.Messages, reserved)
 	}
 }
 
-func (l *SQSListener) handleMessage(message types.Message) {
-	err := l.handler.Handle(message)
+// dispatch hands received messages to the reserved workers and releases the workers that are not needed.
+func (l *SQSListener) dispatch(messages []types.Message, reserved int) {
+	receivedAt := time.Now()
+	l.stats.received.Add(int64(len(messages)))
+	if batchHandler, ok := l.handler.(BatchMessageHandler); ok && len(messages) > 0 {
+		l.workers.release(reserved - 1)
+		l.workers.submit(func() {
+			startedAt := time.Now()
+			l.handleBatch(batchHandler, messages)
+			l.logger.Debugw("message batch handled",
+				"messages", len(messages),
+				"startDelay", startedAt.Sub(receivedAt),
+				"duration", time.Since(startedAt),
+			)
+		})
+		return
+	}
+
+	if IsFifo(l.queueName) {
+		l.dispatchGroups(messages, reserved, receivedAt)
+		return
+	}
+
+	l.workers.release(reserved - len(messages))
+	for _, m := range messages {
+		message := m
+		l.workers.submit(func() {
+			startedAt := time.Now()
+			l.handleMessage(message)
+			l.logger.Debugw("message handled",
+				"messageId", aws.ToString(message.MessageId),
+				"startDelay", startedAt.Sub(receivedAt),
+				"duration", time.Since(startedAt),
+			)
+		})
+	}
+}
+
+// messageOutcome is what happened to a handled message.
+type messageOutcome int
+
+const (
+	messageSucceeded messageOutcome = iota
+	// messageDeadLettered is a permanent failure that was moved to the dead-letter queue.
+	messageDeadLettered
+	// messageFailed is a failure that leaves the message on the queue to be delivered again.
+	messageFailed
+)
+
+// handleMessage runs the handler chain for one message and reports whether it succeeded.
+func (l *SQSListener) handleMessage(message types.Message) bool {
+	return l.processMessage(message, l.startVisibilityHeartbeat(l.handlersContext(), message)) == messageSucceeded
+}
+
+// processMessage runs the handler chain for one message whose visibility heartbeat is already running. The
+// heartbeat is stopped before the message is deleted or released.
+func (l *SQSListener) processMessage(message types.Message, stopHeartbeat func()) messageOutcome {
+	l.stats.inFlight.Add(1)
+	defer l.stats.inFlight.Add(-1)
+	abandoned := new(abandonedHandlers)
+	defer l.waitForAbandoned(abandoned, 1)
+	startedAt := time.Now()
+	resolved, blobKey, err := l.resolveMessage(message)
+	if err == nil {
+		err = l.chain.Handle(withAbandonedHandlers(l.handlerContext(resolved), abandoned), resolved)
+	}
+	l.stats.latencies.record(time.Since(startedAt))
+	stopHeartbeat()
 	if err != nil {
-		l.logger.Error(*message.Body)
-		l.logger.Error(err.Error())
-	} else {
-		err = l.deleteMessage(message)
-		if err != nil {
-			l.logger.Error(err.Error())
+		if errors.Is(err, ErrHandlerTimeout) {
+			l.logSlowHandler(resolved)
 		}
+		l.logger.Error(aws.ToString(message.Body))
+		l.logger.Error(err.Error())
+		l.stats.failed.Add(1)
+		return l.handleFailure(message, err)
+	}
+	l.stats.succeeded.Add(1)
+	l.ack(message, l.blobDeleter(blobKey))
+	return messageSucceeded
+}
+
+// handleFailure moves permanently failed messages to the dead-letter queue and schedules redelivery of the others.
+func (l *SQSListener) handleFailure(message types.Message, err error) messageOutcome {
+	if !IsPermanent(err) {
+		l.scheduleRedelivery(message, err)
+		return messageFailed
+	}
+	if !l.deadLetter(message, err) {
+		return messageFailed
+	}
+	return messageDeadLettered
+}
+
+// ack deletes a successfully handled message, through the delete batcher when batching is enabled. onDeleted, when
+// set, is called once the message is actually deleted, which may be after ack returns.
+func (l *SQSListener) ack(message types.Message, onDeleted func()) {
+	if l.deletes != nil {
+		l.deletes.add(message, onDeleted)
+		return
+	}
+	err := l.deleteMessage(message)
+	if err != nil {
+		l.logger.Error(err.Error())
+		return
+	}
+	l.stats.deleted.Add(1)
+	if onDeleted != nil {
+		onDeleted()
 	}
 }
 
@@ -131,3 +507,17 @@ func (l *SQSListener) deleteMessage(message types.Message) error {
 	}
 	return nil
 }
+
+func (l *SQSListener) concurrency() int {
+	if l.maxConcurrency <= 0 {
+		return DefaultMaxConcurrency
+	}
+	return l.maxConcurrency
+}
+
+func (l *SQSListener) batchSize() int {
+	if l.maxNumberOfMessages <= 0 {
+		return 1
+	}
+	return l.maxNumberOfMessages
+}
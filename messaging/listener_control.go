@@ -0,0 +1,180 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDrained is the reason Run reports when the listener was stopped with Drain.
+var ErrDrained = errors.New("listener drained")
+
+// listenerControl tracks whether a running listener is paused or draining. Every state change closes changed, which
+// cancels the receives in progress.
+type listenerControl struct {
+	mu       sync.Mutex
+	paused   bool
+	draining bool
+	changed  chan struct{}
+	finished chan struct{}
+}
+
+// begin marks the listener as running. The returned function marks it as finished.
+func (c *listenerControl) begin() func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draining = false
+	finished := make(chan struct{})
+	c.finished = finished
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.finished = nil
+		close(finished)
+	}
+}
+
+func (c *listenerControl) setPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused == paused {
+		return
+	}
+	c.paused = paused
+	c.notify()
+}
+
+// drain stops the pollers and returns a channel closed once Run has finished, or nil when it is not running.
+func (c *listenerControl) drain() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.finished == nil {
+		return nil
+	}
+	if !c.draining {
+		c.draining = true
+		c.notify()
+	}
+	return c.finished
+}
+
+func (c *listenerControl) notify() {
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
+// state returns the current state and a channel closed on the next change.
+func (c *listenerControl) state() (paused, draining bool, changed <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.changed == nil {
+		c.changed = make(chan struct{})
+	}
+	return c.paused, c.draining, c.changed
+}
+
+func (c *listenerControl) isPaused() bool {
+	paused, _, _ := c.state()
+	return paused
+}
+
+func (c *listenerControl) isDraining() bool {
+	_, draining, _ := c.state()
+	return draining
+}
+
+// awaitResume blocks while the listener is paused. It returns false when the poller should stop.
+func (c *listenerControl) awaitResume(ctx context.Context, stop <-chan struct{}) bool {
+	for {
+		paused, draining, changed := c.state()
+		if draining {
+			return false
+		}
+		if !paused {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-stop:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// receiveContext returns a context for one receive that is also cancelled when the listener is paused or drained.
+// done releases the context and reports whether the receive was interrupted that way.
+func (c *listenerControl) receiveContext(ctx context.Context) (receiveCtx context.Context, done func() bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	paused, draining, changed := c.state()
+	if paused || draining {
+		cancel()
+		return ctx, func() bool { return true }
+	}
+	go func() {
+		select {
+		case <-changed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() bool {
+		cancel()
+		select {
+		case <-changed:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// Pause stops the listener from receiving new messages and cancels the receives in progress. Messages being
+// handled are not affected.
+func (l *SQSListener) Pause() {
+	l.control.setPaused(true)
+	l.logger.Infow("listener paused", "queue", l.queueName)
+}
+
+// Resume starts receiving again after Pause.
+func (l *SQSListener) Resume() {
+	l.control.setPaused(false)
+	l.logger.Infow("listener resumed", "queue", l.queueName)
+}
+
+// Paused reports whether the listener has been paused.
+func (l *SQSListener) Paused() bool {
+	return l.control.isPaused()
+}
+
+// Drain stops receiving, waits for in-flight messages to be handled and makes Run return ErrDrained. When ctx
+// ends first, the handler contexts are cancelled, pending deletes are flushed and Drain returns how many messages
+// were still being handled together with the error of ctx. Messages that do not finish are redelivered after their
+// visibility timeout. Drain returns right away when the listener is not running.
+func (l *SQSListener) Drain(ctx context.Context) (int, error) {
+	finished := l.control.drain()
+	if finished == nil {
+		return 0, nil
+	}
+	l.logger.Infow("draining listener", "queue", l.queueName)
+
+	select {
+	case <-finished:
+		return 0, nil
+	case <-ctx.Done():
+	}
+
+	abandoned := int(l.stats.inFlight.Load())
+	l.cancelHandlers()
+	if l.deletes != nil {
+		l.deletes.flushPending()
+	}
+	l.logger.Warnw("listener drain deadline passed",
+		"queue", l.queueName,
+		"abandoned", abandoned,
+	)
+	return abandoned, ctx.Err()
+}
@@ -0,0 +1,157 @@
+package zaws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func blockUntilReceiveCancelled(ctx context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSQSListener_Pause(t *testing.T) {
+	t.Run("Pause cancels the receive in progress and Resume starts receiving again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.maxConcurrency = 1
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		sut.handler = NewMockMessageHandler(ctrl)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var pausedDuringReceive bool
+		resumed := make(chan struct{})
+
+		gomock.InOrder(
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				sut.Pause()
+				<-ctx.Done()
+				pausedDuringReceive = sut.Stats().Paused
+				go func() {
+					time.Sleep(20 * time.Millisecond)
+					close(resumed)
+					sut.Resume()
+				}()
+				return nil, ctx.Err()
+			}),
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, *sqs.ReceiveMessageInput, ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				select {
+				case <-resumed:
+				default:
+					t.Error("received while paused")
+				}
+				cancel()
+				return nil, context.Canceled
+			}),
+		)
+
+		err := sut.Run(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.True(t, pausedDuringReceive)
+		assert.False(t, sut.Paused())
+		assert.Zero(t, sut.Stats().ConsecutivePollErrors)
+	})
+}
+
+func TestSQSListener_Drain(t *testing.T) {
+	message := types.Message{MessageId: aws.String("test-id"), ReceiptHandle: aws.String("test handle")}
+
+	newDrainTest := func(t *testing.T) (*SQSListener, *MockISQSClient, *MockMessageHandler) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		handler := NewMockMessageHandler(ctrl)
+		sut.handler = handler
+		gomock.InOrder(
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{message}}, nil),
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).DoAndReturn(blockUntilReceiveCancelled).AnyTimes(),
+		)
+		return sut, sqsClient, handler
+	}
+
+	t.Run("Drain waits for in-flight messages and makes Run return ErrDrained", func(t *testing.T) {
+		sut, sqsClient, handler := newDrainTest(t)
+		started := make(chan struct{})
+		handler.EXPECT().Handle(message).DoAndReturn(func(types.Message) error {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil)
+		runErr := make(chan error)
+		go func() { runErr <- sut.Run(context.Background()) }()
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		abandoned, err := sut.Drain(ctx)
+
+		assert.Nil(t, err)
+		assert.Zero(t, abandoned)
+		assert.ErrorIs(t, <-runErr, ErrDrained)
+		assert.Equal(t, int64(1), sut.Stats().Deleted)
+	})
+
+	t.Run("Drain reports the messages still in flight when its context ends", func(t *testing.T) {
+		sut, _, handler := newDrainTest(t)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		handler.EXPECT().Handle(message).DoAndReturn(func(types.Message) error {
+			close(started)
+			<-release
+			return assert.AnError
+		})
+		runErr := make(chan error)
+		go func() { runErr <- sut.Run(context.Background()) }()
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		abandoned, err := sut.Drain(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 1, abandoned)
+		close(release)
+		assert.ErrorIs(t, <-runErr, ErrDrained)
+	})
+
+	t.Run("Drain cancels the handler contexts when its context ends", func(t *testing.T) {
+		sut, _, _ := newDrainTest(t)
+		started := make(chan struct{})
+		sut.contextHandler = ContextMessageHandlerFunc(func(ctx context.Context, _ types.Message) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		runErr := make(chan error)
+		go func() { runErr <- sut.Run(context.Background()) }()
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := sut.Drain(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, <-runErr, ErrDrained)
+		assert.Equal(t, int64(1), sut.Stats().Failed)
+	})
+
+	t.Run("Drain returns right away when the listener is not running", func(t *testing.T) {
+		sut := getTestListener()
+
+		abandoned, err := sut.Drain(context.Background())
+
+		assert.Nil(t, err)
+		assert.Zero(t, abandoned)
+	})
+}
@@ -6,8 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ammyy9908/go-common-libraries/messaging/mock"
-
 	"github.com/ammyy9908/go-common-libraries/gracefulshutdown"
 	"github.com/ammyy9908/go-common-libraries/logger"
 
@@ -69,61 +67,77 @@ func TestNewListener(t *testing.T) {
 	})
 }
 
-func TestSQSListener_poll(t *testing.T) {
-	ctx := context.Background()
+func blockUntilCancelled(ctx context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSQSListener_Run(t *testing.T) {
 	var mockController = gomock.NewController(t)
-	t.Run("poll should stop processing when it receives a value in the shutdown channel", func(t *testing.T) {
+	t.Run("Run cancels a blocked receive and returns the context error when the context is cancelled", func(t *testing.T) {
 		sut := getTestListener()
-		sqsClient := mock.NewMockISQSClient(mockController)
-		handler := mock.NewMockMessageHandler(mockController)
+		sut.gracefulShutdownManager = nil
+		sqsClient := NewMockISQSClient(mockController)
+		handler := NewMockMessageHandler(mockController)
 		sut.handler = handler
 		sut.sqsClient = sqsClient
-		go func() {
-			time.Sleep(time.Second * 1)
-			sut.gracefulShutdownManager.ShutdownChannel <- 1
-		}()
-		receiveMessageInput := sqs.ReceiveMessageInput{}
-		receiveMessageResponse := sqs.ReceiveMessageOutput{}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
 		sqsClient.
 			EXPECT().
-			ReceiveMessage(ctx, &receiveMessageInput).
-			Return(&receiveMessageResponse, nil).
-			AnyTimes()
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(blockUntilCancelled)
+		handler.EXPECT().Handle(gomock.Any()).Times(0)
 
-		sut.poll(&receiveMessageInput)
+		err := sut.Run(ctx)
 
-		// Since we have called poll function synchronously (without a go routine), we guarantee that it has exited the for loop here.
-		handler.EXPECT().Handle(gomock.Any()).Times(0)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
 	})
 
-	t.Run("poll should handle message when it receives a message in the listener and deletes processed messages", func(t *testing.T) {
+	t.Run("Run stops processing and returns ErrShutdownRequested when the shutdown channel is closed", func(t *testing.T) {
 		sut := getTestListener()
-		sqsClient := mock.NewMockISQSClient(mockController)
-		handler := mock.NewMockMessageHandler(mockController)
+		sqsClient := NewMockISQSClient(mockController)
+		handler := NewMockMessageHandler(mockController)
 		sut.handler = handler
 		sut.sqsClient = sqsClient
-
 		go func() {
-			time.Sleep(time.Second * 1)
-			sut.gracefulShutdownManager.ShutdownChannel <- 1
+			time.Sleep(100 * time.Millisecond)
+			close(sut.gracefulShutdownManager.ShutdownChannel)
 		}()
 
-		receiveMessageInput := sqs.ReceiveMessageInput{}
-		receiveMessageResponse := sqs.ReceiveMessageOutput{}
-		message := types.Message{}
-		var messages []types.Message
-		messages = append(messages, message)
-		outputResponseWithMessage := sqs.ReceiveMessageOutput{Messages: messages}
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(blockUntilCancelled)
+		handler.EXPECT().Handle(gomock.Any()).Times(0)
+
+		err := sut.Run(context.Background())
+
+		assert.ErrorIs(t, err, ErrShutdownRequested)
+		sut.gracefulShutdownManager.ShutdownWaitGroup.Wait()
+	})
+
+	t.Run("Run should handle message when it receives a message in the listener and deletes processed messages", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(mockController)
+		handler := NewMockMessageHandler(mockController)
+		sut.handler = handler
+		sut.sqsClient = sqsClient
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		message := types.Message{Body: aws.String("test"), ReceiptHandle: aws.String("test handle")}
+		outputResponseWithMessage := sqs.ReceiveMessageOutput{Messages: []types.Message{message}}
 
 		first := sqsClient.
 			EXPECT().
-			ReceiveMessage(ctx, gomock.Any()).
+			ReceiveMessage(gomock.Any(), gomock.Any()).
 			Return(&outputResponseWithMessage, nil)
 		second := sqsClient.
 			EXPECT().
-			ReceiveMessage(ctx, &receiveMessageInput).
-			Return(&receiveMessageResponse, nil).
-			AnyTimes()
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(blockUntilCancelled)
 		gomock.InOrder(first, second)
 
 		handler.
@@ -132,63 +146,164 @@ func TestSQSListener_poll(t *testing.T) {
 			Return(nil)
 		sqsClient.
 			EXPECT().
-			DeleteMessage(ctx, gomock.Any()).
-			Return(&sqs.DeleteMessageOutput{}, nil)
+			DeleteMessage(gomock.Any(), &sqs.DeleteMessageInput{
+				QueueUrl:      sut.queueURL,
+				ReceiptHandle: aws.String("test handle"),
+			}).
+			DoAndReturn(func(context.Context, *sqs.DeleteMessageInput, ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+				cancel()
+				return &sqs.DeleteMessageOutput{}, nil
+			})
+
+		err := sut.Run(ctx)
 
-		sut.poll(&receiveMessageInput)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
 
-		// Since we have called poll function synchronously (without a go routine), we guarantee that it has exited the for loop here.
+	t.Run("Run flushes batched deletes of handled messages before returning", func(t *testing.T) {
+		sut := getTestListener()
+		sut.deleteBatchSize = MaxDeleteBatchSize
+		sut.deleteFlushInterval = time.Hour
+		sqsClient := NewMockISQSClient(mockController)
+		handler := NewMockMessageHandler(mockController)
+		sut.handler = handler
+		sut.sqsClient = sqsClient
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 
+		message := types.Message{Body: aws.String("test"), ReceiptHandle: aws.String("test handle")}
+		first := sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{message}}, nil)
+		second := sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(blockUntilCancelled)
+		gomock.InOrder(first, second)
+
+		handler.
+			EXPECT().
+			Handle(message).
+			DoAndReturn(func(types.Message) error {
+				cancel()
+				return nil
+			})
+		sqsClient.
+			EXPECT().
+			DeleteMessageBatch(gomock.Any(), &sqs.DeleteMessageBatchInput{
+				QueueUrl: sut.queueURL,
+				Entries: []types.DeleteMessageBatchRequestEntry{
+					{Id: aws.String("0"), ReceiptHandle: aws.String("test handle")},
+				},
+			}).
+			Return(&sqs.DeleteMessageBatchOutput{}, nil)
+
+		err := sut.Run(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
 	})
 
-	t.Run("poll should not delete message when handler returns error", func(t *testing.T) {
+	t.Run("Run should not delete message when handler returns error", func(t *testing.T) {
 		sut := getTestListener()
-		sqsClient := mock.NewMockISQSClient(mockController)
-		handler := mock.NewMockMessageHandler(mockController)
+		sqsClient := NewMockISQSClient(mockController)
+		handler := NewMockMessageHandler(mockController)
 		sut.handler = handler
 		sut.sqsClient = sqsClient
-		go func() {
-			time.Sleep(time.Second * 1)
-			sut.gracefulShutdownManager.ShutdownChannel <- 1
-		}()
-		receiveMessageInput := sqs.ReceiveMessageInput{}
-		receiveMessageResponse := sqs.ReceiveMessageOutput{}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
 		message := types.Message{}
-		var messages []types.Message
-		messages = append(messages, message)
-		outputResponseWithMessage := sqs.ReceiveMessageOutput{Messages: messages}
+		outputResponseWithMessage := sqs.ReceiveMessageOutput{Messages: []types.Message{message}}
 		first := sqsClient.
 			EXPECT().
-			ReceiveMessage(ctx, gomock.Any()).
+			ReceiveMessage(gomock.Any(), gomock.Any()).
 			Return(&outputResponseWithMessage, nil)
 		second := sqsClient.
 			EXPECT().
-			ReceiveMessage(ctx, &receiveMessageInput).
-			Return(&receiveMessageResponse, nil).
-			AnyTimes()
-
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(blockUntilCancelled)
 		gomock.InOrder(first, second)
 
-		handlerErr := errors.New("random error")
 		handler.
 			EXPECT().
 			Handle(message).
-			Return(handlerErr)
+			DoAndReturn(func(types.Message) error {
+				cancel()
+				return errors.New("random error")
+			})
 		sqsClient.
 			EXPECT().
-			DeleteMessage(ctx, gomock.Any()).
+			DeleteMessage(gomock.Any(), gomock.Any()).
 			Times(0)
-		sut.poll(&receiveMessageInput)
 
-		// Since we have called poll function synchronously (without a go routine), we guarantee that it has exited the for loop here.
+		err := sut.Run(ctx)
 
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestSQSListener_poll(t *testing.T) {
+	var mockController = gomock.NewController(t)
+	t.Run("poll does not receive more messages than there are free workers", func(t *testing.T) {
+		sut := getTestListener()
+		sut.maxNumberOfMessages = 10
+		sqsClient := NewMockISQSClient(mockController)
+		handler := NewMockMessageHandler(mockController)
+		sut.handler = handler
+		sut.sqsClient = sqsClient
+		sut.workers = newWorkerPool(2)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), &sqs.ReceiveMessageInput{MaxNumberOfMessages: 2}).
+			DoAndReturn(func(context.Context, *sqs.ReceiveMessageInput, ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				cancel()
+				return nil, context.Canceled
+			})
+
+		sut.poll(ctx, &sqs.ReceiveMessageInput{}, nil)
+		sut.workers.stop()
+	})
+
+	t.Run("poll stops receiving while every worker is busy", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(mockController)
+		handler := NewMockMessageHandler(mockController)
+		sut.handler = handler
+		sut.chain = AdaptMessageHandler(handler)
+		sut.sqsClient = sqsClient
+		sut.workers = newWorkerPool(1)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		release := make(chan struct{})
+
+		message := types.Message{Body: aws.String("test")}
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{message}}, nil).
+			Times(1)
+		handler.
+			EXPECT().
+			Handle(message).
+			DoAndReturn(func(types.Message) error {
+				<-release
+				return errors.New("still failing")
+			})
+
+		sut.poll(ctx, &sqs.ReceiveMessageInput{}, nil)
+		close(release)
+		sut.workers.stop()
 	})
 }
 
 func TestSQSListener_deleteMessage(t *testing.T) {
 	ctx := context.Background()
 	l := getTestListener()
-	sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
 
 	msg := types.Message{
 		Body:          aws.String("test"),
@@ -3,11 +3,13 @@ package zaws
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 const (
@@ -19,10 +21,18 @@ const (
 type IManager interface {
 	CreateQueue(queueName string, config QueueConfig) (*sqs.CreateQueueOutput, error)
 	CreateTopic(topicName string, tags map[string]string) (*sns.CreateTopicOutput, error)
+	CreateTopicWithConfig(topicName string, config TopicConfig) (*sns.CreateTopicOutput, error)
 	SubscribeQueueToTopic(queueName, topicName string, raw bool) error
 	GetTopicArn(topicName string) (string, error)
 	GetQueueArn(queueName string) (string, error)
 	SubscribeQueueToTopicV2(queueName, topicName string, raw bool)
+	PeekDeadLetters(ctx context.Context, queueName string, max int) ([]types.Message, error)
+	RedriveDeadLetters(ctx context.Context, queueName string, config RedriveConfig) (RedriveResult, error)
+}
+
+type TopicConfig struct {
+	Tags                      map[string]string
+	ContentBasedDeduplication bool // Only used by FIFO topics, whose names end with FifoSuffix
 }
 
 type Manager struct {
@@ -70,16 +80,27 @@ func (m *Manager) CreateQueue(queueName string, config QueueConfig) (*sqs.Create
 }
 
 func (m *Manager) CreateTopic(topicName string, tags map[string]string) (*sns.CreateTopicOutput, error) {
+	return m.CreateTopicWithConfig(topicName, TopicConfig{Tags: tags})
+}
+
+func (m *Manager) CreateTopicWithConfig(topicName string, config TopicConfig) (*sns.CreateTopicOutput, error) {
 	ctx := context.Background()
-	topicTags := createTopicTags(tags)
+	topicTags := createTopicTags(config.Tags)
 	if topicTags == nil {
 		return nil, errors.New(ErrMissingTags)
 	}
 
-	result, err := m.snsClient.CreateTopic(ctx, &sns.CreateTopicInput{
+	input := &sns.CreateTopicInput{
 		Name: aws.String(topicName),
 		Tags: topicTags,
-	})
+	}
+	if IsFifo(topicName) {
+		input.Attributes = map[string]string{
+			"FifoTopic":                 "true",
+			"ContentBasedDeduplication": strconv.FormatBool(config.ContentBasedDeduplication),
+		}
+	}
+	result, err := m.snsClient.CreateTopic(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -5,10 +5,12 @@
 package zaws
 
 import (
+	context "context"
 	reflect "reflect"
 
 	sns "github.com/aws/aws-sdk-go-v2/service/sns"
 	sqs "github.com/aws/aws-sdk-go-v2/service/sqs"
+	types "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -65,6 +67,81 @@ func (mr *MockIManagerMockRecorder) CreateTopic(topicName, tags interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopic", reflect.TypeOf((*MockIManager)(nil).CreateTopic), topicName, tags)
 }
 
+// CreateTopicWithConfig mocks base method.
+func (m *MockIManager) CreateTopicWithConfig(topicName string, config TopicConfig) (*sns.CreateTopicOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTopicWithConfig", topicName, config)
+	ret0, _ := ret[0].(*sns.CreateTopicOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTopicWithConfig indicates an expected call of CreateTopicWithConfig.
+func (mr *MockIManagerMockRecorder) CreateTopicWithConfig(topicName, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopicWithConfig", reflect.TypeOf((*MockIManager)(nil).CreateTopicWithConfig), topicName, config)
+}
+
+// GetQueueArn mocks base method.
+func (m *MockIManager) GetQueueArn(queueName string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQueueArn", queueName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQueueArn indicates an expected call of GetQueueArn.
+func (mr *MockIManagerMockRecorder) GetQueueArn(queueName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueueArn", reflect.TypeOf((*MockIManager)(nil).GetQueueArn), queueName)
+}
+
+// GetTopicArn mocks base method.
+func (m *MockIManager) GetTopicArn(topicName string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopicArn", topicName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopicArn indicates an expected call of GetTopicArn.
+func (mr *MockIManagerMockRecorder) GetTopicArn(topicName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopicArn", reflect.TypeOf((*MockIManager)(nil).GetTopicArn), topicName)
+}
+
+// PeekDeadLetters mocks base method.
+func (m *MockIManager) PeekDeadLetters(ctx context.Context, queueName string, max int) ([]types.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeekDeadLetters", ctx, queueName, max)
+	ret0, _ := ret[0].([]types.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeekDeadLetters indicates an expected call of PeekDeadLetters.
+func (mr *MockIManagerMockRecorder) PeekDeadLetters(ctx, queueName, max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeekDeadLetters", reflect.TypeOf((*MockIManager)(nil).PeekDeadLetters), ctx, queueName, max)
+}
+
+// RedriveDeadLetters mocks base method.
+func (m *MockIManager) RedriveDeadLetters(ctx context.Context, queueName string, config RedriveConfig) (RedriveResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedriveDeadLetters", ctx, queueName, config)
+	ret0, _ := ret[0].(RedriveResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedriveDeadLetters indicates an expected call of RedriveDeadLetters.
+func (mr *MockIManagerMockRecorder) RedriveDeadLetters(ctx, queueName, config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedriveDeadLetters", reflect.TypeOf((*MockIManager)(nil).RedriveDeadLetters), ctx, queueName, config)
+}
+
 // SubscribeQueueToTopic mocks base method.
 func (m *MockIManager) SubscribeQueueToTopic(queueName, topicName string, raw bool) error {
 	m.ctrl.T.Helper()
@@ -78,3 +155,15 @@ func (mr *MockIManagerMockRecorder) SubscribeQueueToTopic(queueName, topicName,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeQueueToTopic", reflect.TypeOf((*MockIManager)(nil).SubscribeQueueToTopic), queueName, topicName, raw)
 }
+
+// SubscribeQueueToTopicV2 mocks base method.
+func (m *MockIManager) SubscribeQueueToTopicV2(queueName, topicName string, raw bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscribeQueueToTopicV2", queueName, topicName, raw)
+}
+
+// SubscribeQueueToTopicV2 indicates an expected call of SubscribeQueueToTopicV2.
+func (mr *MockIManagerMockRecorder) SubscribeQueueToTopicV2(queueName, topicName, raw interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeQueueToTopicV2", reflect.TypeOf((*MockIManager)(nil).SubscribeQueueToTopicV2), queueName, topicName, raw)
+}
@@ -6,8 +6,6 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/ammyy9908/go-common-libraries/messaging/mock"
-
 	"github.com/aws/smithy-go/middleware"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -73,8 +71,8 @@ func TestManager_CreateQueue(t *testing.T) {
 	}
 
 	ctrl := gomock.NewController(t)
-	snsClient := mock.NewMockISNSClient(ctrl)
-	sqsClient := mock.NewMockISQSClient(ctrl)
+	snsClient := NewMockISNSClient(ctrl)
+	sqsClient := NewMockISQSClient(ctrl)
 	ctx := context.Background()
 
 	t.Run("CreateQueue creates a queue when given a name and tags", func(t *testing.T) {
@@ -307,8 +305,8 @@ func TestManager_CreateQueue(t *testing.T) {
 func TestManager_CreateTopic(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
-	snsClient := mock.NewMockISNSClient(ctrl)
-	sqsClient := mock.NewMockISQSClient(ctrl)
+	snsClient := NewMockISNSClient(ctrl)
+	sqsClient := NewMockISQSClient(ctrl)
 
 	topicName := "test-topic"
 
@@ -376,13 +374,37 @@ func TestManager_CreateTopic(t *testing.T) {
 		assert.Nil(t, got)
 		assert.Equal(t, errors.New(ErrMissingTags), err)
 	})
+
+	t.Run("CreateTopicWithConfig creates a FIFO topic when the topic name ends with the fifo suffix", func(t *testing.T) {
+		snsClient.
+			EXPECT().
+			CreateTopic(ctx, &sns.CreateTopicInput{
+				Name: aws.String("test-topic.fifo"),
+				Tags: createTopicTags(tags),
+				Attributes: map[string]string{
+					"FifoTopic":                 "true",
+					"ContentBasedDeduplication": "true",
+				},
+			}).
+			Return(&sns.CreateTopicOutput{TopicArn: aws.String("arn:test-topic.fifo")}, nil)
+
+		m := &Manager{
+			snsClient: snsClient,
+			sqsClient: sqsClient,
+		}
+
+		got, err := m.CreateTopicWithConfig("test-topic.fifo", TopicConfig{Tags: tags, ContentBasedDeduplication: true})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "arn:test-topic.fifo", aws.ToString(got.TopicArn))
+	})
 }
 
 func TestManager_subscribe(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
-	snsClient := mock.NewMockISNSClient(ctrl)
-	sqsClient := mock.NewMockISQSClient(ctrl)
+	snsClient := NewMockISNSClient(ctrl)
+	sqsClient := NewMockISQSClient(ctrl)
 	raw := true
 
 	t.Run("Subscribe subscribes a queue to a topic and does not return error when given queue name and topic name", func(t *testing.T) {
@@ -438,8 +460,8 @@ func TestManager_subscribe(t *testing.T) {
 func TestManager_SubscribeQueueToTopic(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
-	snsClient := mock.NewMockISNSClient(ctrl)
-	sqsClient := mock.NewMockISQSClient(ctrl)
+	snsClient := NewMockISNSClient(ctrl)
+	sqsClient := NewMockISQSClient(ctrl)
 	topicName := "test-topic-name"
 	topicArn := "arn:aws:sns:ap-south-1:00000000:test-topic"
 	queueArn := "test-queue-arn"
@@ -0,0 +1,77 @@
+package zaws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// dispatchGroups is used for FIFO queues. Each message group becomes one job that handles its messages in the
+// order they were received, so different groups still run in parallel.
+func (l *SQSListener) dispatchGroups(messages []types.Message, reserved int, receivedAt time.Time) {
+	groups := messageGroups(messages)
+	l.workers.release(reserved - len(groups))
+	for _, g := range groups {
+		group := g
+		l.workers.submit(func() {
+			startedAt := time.Now()
+			l.handleGroup(group)
+			l.logger.Debugw("message group handled",
+				"messageGroupId", messageGroupID(group[0]),
+				"messages", len(group),
+				"startDelay", startedAt.Sub(receivedAt),
+				"duration", time.Since(startedAt),
+			)
+		})
+	}
+}
+
+// handleGroup keeps the visibility of every message in the group extended from the start, since the last ones wait
+// for all the others. It stops at the first message that failed and stays on the queue. The messages after it are
+// not deleted, so SQS delivers them again after the failed one and the order of the group is kept. Messages moved to
+// the dead-letter queue do not stop the group.
+func (l *SQSListener) handleGroup(group []types.Message) {
+	stopHeartbeats := make([]func(), len(group))
+	for i, message := range group {
+		stopHeartbeats[i] = l.startVisibilityHeartbeat(l.handlersContext(), message)
+	}
+
+	for i, message := range group {
+		if l.processMessage(message, stopHeartbeats[i]) != messageFailed {
+			continue
+		}
+		for _, stop := range stopHeartbeats[i+1:] {
+			stop()
+		}
+		if skipped := len(group) - i - 1; skipped > 0 {
+			l.logger.Warnw("skipped rest of message group after a failure",
+				"messageGroupId", messageGroupID(message),
+				"messageId", aws.ToString(message.MessageId),
+				"skipped", skipped,
+			)
+		}
+		return
+	}
+}
+
+// messageGroups splits messages by message group, keeping the order of the messages within each group.
+func messageGroups(messages []types.Message) [][]types.Message {
+	var groups [][]types.Message
+	index := make(map[string]int)
+	for _, message := range messages {
+		groupID := messageGroupID(message)
+		i, ok := index[groupID]
+		if !ok {
+			i = len(groups)
+			index[groupID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], message)
+	}
+	return groups
+}
+
+func messageGroupID(message types.Message) string {
+	return message.Attributes[string(types.MessageSystemAttributeNameMessageGroupId)]
+}
@@ -0,0 +1,140 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func groupedMessage(id, groupID string) types.Message {
+	return types.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String(id + "-handle"),
+		Attributes: map[string]string{
+			string(types.MessageSystemAttributeNameMessageGroupId): groupID,
+		},
+	}
+}
+
+func Test_messageGroups(t *testing.T) {
+	t.Run("messageGroups splits messages by group and keeps their order", func(t *testing.T) {
+		a1, b1, a2, c1, b2 := groupedMessage("a1", "a"), groupedMessage("b1", "b"), groupedMessage("a2", "a"),
+			groupedMessage("c1", "c"), groupedMessage("b2", "b")
+
+		got := messageGroups([]types.Message{a1, b1, a2, c1, b2})
+
+		assert.Equal(t, [][]types.Message{{a1, a2}, {b1, b2}, {c1}}, got)
+	})
+}
+
+func TestSQSListener_handleGroup(t *testing.T) {
+	t.Run("handleGroup stops at the first failed message and leaves the rest of the group on the queue", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.queueName = "test-queue.fifo"
+		sqsClient := NewMockISQSClient(ctrl)
+		handler := NewMockMessageHandler(ctrl)
+		sut.sqsClient = sqsClient
+		sut.chain = AdaptMessageHandler(handler)
+		first, second, third := groupedMessage("1", "a"), groupedMessage("2", "a"), groupedMessage("3", "a")
+
+		gomock.InOrder(
+			handler.EXPECT().Handle(first).Return(nil),
+			handler.EXPECT().Handle(second).Return(errors.New("handler error")),
+		)
+		handler.EXPECT().Handle(third).Times(0)
+		sqsClient.
+			EXPECT().
+			DeleteMessage(gomock.Any(), &sqs.DeleteMessageInput{
+				QueueUrl:      sut.queueURL,
+				ReceiptHandle: aws.String("1-handle"),
+			}).
+			Return(&sqs.DeleteMessageOutput{}, nil).
+			Times(1)
+
+		sut.handleGroup([]types.Message{first, second, third})
+	})
+	t.Run("handleGroup continues after a message that was moved to the dead-letter queue", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.queueName = "test-queue.fifo"
+		sut.dlqURL = "test-queue_ERROR.fifo"
+		sqsClient := NewMockISQSClient(ctrl)
+		handler := NewMockMessageHandler(ctrl)
+		sut.sqsClient = sqsClient
+		sut.chain = AdaptMessageHandler(handler)
+		first, second := groupedMessage("1", "a"), groupedMessage("2", "a")
+
+		gomock.InOrder(
+			handler.EXPECT().Handle(first).Return(Permanent(errors.New("malformed"))),
+			handler.EXPECT().Handle(second).Return(nil),
+		)
+		sqsClient.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Return(&sqs.SendMessageOutput{}, nil)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil).Times(2)
+
+		sut.handleGroup([]types.Message{first, second})
+	})
+
+	t.Run("handleGroup stops when a permanently failed message cannot be moved to the dead-letter queue", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.queueName = "test-queue.fifo"
+		sut.dlqURL = "test-queue_ERROR.fifo"
+		sqsClient := NewMockISQSClient(ctrl)
+		handler := NewMockMessageHandler(ctrl)
+		sut.sqsClient = sqsClient
+		sut.chain = AdaptMessageHandler(handler)
+		first, second := groupedMessage("1", "a"), groupedMessage("2", "a")
+
+		handler.EXPECT().Handle(first).Return(Permanent(errors.New("malformed")))
+		handler.EXPECT().Handle(second).Times(0)
+		sqsClient.EXPECT().SendMessage(gomock.Any(), gomock.Any()).Return(nil, errors.New("send error"))
+
+		sut.handleGroup([]types.Message{first, second})
+	})
+
+	t.Run("handleGroup extends the visibility of the messages waiting for an earlier one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.queueName = "test-queue.fifo"
+		sut.visibilityHeartbeatInterval = 10 * time.Millisecond
+		sqsClient := NewMockISQSClient(ctrl)
+		handler := NewMockMessageHandler(ctrl)
+		sut.sqsClient = sqsClient
+		sut.chain = AdaptMessageHandler(handler)
+		first, second := groupedMessage("1", "a"), groupedMessage("2", "a")
+		extended := make(chan struct{})
+		var once sync.Once
+
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sqs.ChangeMessageVisibilityInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+				if aws.ToString(input.ReceiptHandle) == "2-handle" {
+					once.Do(func() { close(extended) })
+				}
+				return &sqs.ChangeMessageVisibilityOutput{}, nil
+			}).
+			AnyTimes()
+		handler.EXPECT().Handle(first).DoAndReturn(func(types.Message) error {
+			select {
+			case <-extended:
+			case <-time.After(time.Second):
+				t.Error("visibility of the waiting message was not extended")
+			}
+			return nil
+		})
+		handler.EXPECT().Handle(second).Return(nil)
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil).Times(2)
+
+		sut.handleGroup([]types.Message{first, second})
+	})
+}
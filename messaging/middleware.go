@@ -0,0 +1,199 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/ammyy9908/go-common-libraries/correlation"
+	"github.com/ammyy9908/go-common-libraries/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+var ErrHandlerTimeout = errors.New("message handler timed out")
+
+// Middleware wraps a MessageHandler to add behaviour before or after it handles a message.
+type Middleware func(next MessageHandler) MessageHandler
+
+// ContextMiddleware wraps a ContextMessageHandler, for middlewares that need the handler context.
+type ContextMiddleware func(next ContextMessageHandler) ContextMessageHandler
+
+type MessageHandlerFunc func(message types.Message) error
+
+func (f MessageHandlerFunc) Handle(message types.Message) error {
+	return f(message)
+}
+
+// Chain wraps handler with middlewares. The first middleware is the outermost one and sees the message first.
+func Chain(handler MessageHandler, middlewares ...Middleware) MessageHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// ChainContext is Chain for a ContextMessageHandler.
+func ChainContext(handler ContextMessageHandler, middlewares ...ContextMiddleware) ContextMessageHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// AdaptMiddleware turns a Middleware into a ContextMiddleware that passes the context on to the next handler.
+func AdaptMiddleware(middleware Middleware) ContextMiddleware {
+	return func(next ContextMessageHandler) ContextMessageHandler {
+		return ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+			return middleware(MessageHandlerFunc(func(message types.Message) error {
+				return next.Handle(ctx, message)
+			})).Handle(message)
+		})
+	}
+}
+
+// Recover turns a panic in the wrapped handler into an error so that a bad message cannot crash the process.
+func Recover(log *zap.SugaredLogger) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return MessageHandlerFunc(func(message types.Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorw("message handler panicked",
+						"messageId", aws.ToString(message.MessageId),
+						"panic", r,
+						"stack", string(debug.Stack()),
+					)
+					err = fmt.Errorf("message handler panicked: %v", r)
+				}
+			}()
+			return next.Handle(message)
+		})
+	}
+}
+
+// Logging logs the outcome and duration of every handled message.
+func Logging(log *zap.SugaredLogger) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return MessageHandlerFunc(func(message types.Message) error {
+			start := time.Now()
+			err := next.Handle(message)
+			fields := []interface{}{
+				"messageId", aws.ToString(message.MessageId),
+				logger.CorrelationID, correlationIDFromMessage(message),
+				"duration", time.Since(start),
+			}
+			if err != nil {
+				log.Errorw("message handling failed", append(fields, "error", err)...)
+				return err
+			}
+			log.Infow("message handled", fields...)
+			return nil
+		})
+	}
+}
+
+// Timeout stops waiting for the wrapped handler after d and returns ErrHandlerTimeout. The handler itself
+// cannot be interrupted and keeps running in the background, where a listener no longer counts it against
+// MaxConcurrency. Use ContextTimeout or ListenerConfig.HandlerTimeout to cancel its context and keep its worker busy.
+func Timeout(d time.Duration) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return MessageHandlerFunc(func(message types.Message) error {
+			done := make(chan error, 1)
+			go func() {
+				done <- next.Handle(message)
+			}()
+
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case err := <-done:
+				return err
+			case <-timer.C:
+				return fmt.Errorf("%w after %s", ErrHandlerTimeout, d)
+			}
+		})
+	}
+}
+
+// ContextTimeout cancels the context of the wrapped handler after d and returns ErrHandlerTimeout. A handler that
+// ignores its context keeps running in the background. In a listener it keeps its worker busy until it returns.
+func ContextTimeout(d time.Duration) ContextMiddleware {
+	return func(next ContextMessageHandler) ContextMessageHandler {
+		return ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			done := make(chan error, 1)
+			finished := make(chan struct{})
+			go func() {
+				defer close(finished)
+				done <- next.Handle(ctx, message)
+			}()
+
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				abandon(ctx, finished)
+				return fmt.Errorf("%w after %s", ErrHandlerTimeout, d)
+			}
+		})
+	}
+}
+
+// Correlation makes sure every message carries an X-Correlation-ID message attribute, generating a new ID
+// when the publisher did not set one.
+func Correlation() Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return MessageHandlerFunc(func(message types.Message) error {
+			if correlationIDFromMessage(message) != "" {
+				return next.Handle(message)
+			}
+			return next.Handle(withCorrelationID(message, correlation.NewId()))
+		})
+	}
+}
+
+// ContextCorrelation is Correlation for a ContextMessageHandler. A message without a correlation ID gets the one of
+// the handler context, or a new ID when the context has none.
+func ContextCorrelation() ContextMiddleware {
+	return func(next ContextMessageHandler) ContextMessageHandler {
+		return ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+			if correlationIDFromMessage(message) != "" {
+				return next.Handle(ctx, message)
+			}
+
+			correlationID, err := correlation.FromContext(ctx)
+			if err != nil {
+				correlationID = correlation.NewId()
+			}
+			return next.Handle(ctx, withCorrelationID(message, correlationID))
+		})
+	}
+}
+
+// withCorrelationID returns a copy of message with the X-Correlation-ID attribute set, leaving message unchanged.
+func withCorrelationID(message types.Message, correlationID string) types.Message {
+	attributes := make(map[string]types.MessageAttributeValue, len(message.MessageAttributes)+1)
+	for key, value := range message.MessageAttributes {
+		attributes[key] = value
+	}
+	attributes[logger.CorrelationID] = types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(correlationID),
+	}
+	message.MessageAttributes = attributes
+	return message
+}
+
+func correlationIDFromMessage(message types.Message) string {
+	attribute, ok := message.MessageAttributes[logger.CorrelationID]
+	if !ok {
+		return ""
+	}
+	return aws.ToString(attribute.StringValue)
+}
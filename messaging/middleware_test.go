@@ -0,0 +1,272 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ammyy9908/go-common-libraries/correlation"
+	"github.com/ammyy9908/go-common-libraries/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestChain(t *testing.T) {
+	t.Run("Chain applies middlewares so that the first one is the outermost", func(t *testing.T) {
+		var calls []string
+		record := func(name string) Middleware {
+			return func(next MessageHandler) MessageHandler {
+				return MessageHandlerFunc(func(message types.Message) error {
+					calls = append(calls, name)
+					return next.Handle(message)
+				})
+			}
+		}
+		handler := MessageHandlerFunc(func(message types.Message) error {
+			calls = append(calls, "handler")
+			return nil
+		})
+
+		err := Chain(handler, record("first"), record("second")).Handle(types.Message{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"first", "second", "handler"}, calls)
+	})
+
+	t.Run("Chain works with a MultiTopicHandler", func(t *testing.T) {
+		mtH := NewMultiTopicHandler()
+		mtH.RegisterHandler("test-topic", func(message string) error {
+			panic("bad message")
+		})
+		message := types.Message{Body: aws.String(`{"subject":"test-topic","message":"test message"}`)}
+
+		err := Chain(mtH, Recover(zap.NewNop().Sugar())).Handle(message)
+
+		assert.EqualError(t, err, "message handler panicked: bad message")
+	})
+}
+
+func TestRecover(t *testing.T) {
+	t.Run("Recover returns the handler error when the handler does not panic", func(t *testing.T) {
+		handlerErr := errors.New("test error")
+		handler := MessageHandlerFunc(func(message types.Message) error { return handlerErr })
+
+		err := Recover(zap.NewNop().Sugar())(handler).Handle(types.Message{})
+
+		assert.Equal(t, handlerErr, err)
+	})
+
+	t.Run("Recover turns a panic into an error", func(t *testing.T) {
+		handler := MessageHandlerFunc(func(message types.Message) error {
+			var m map[string]string
+			m["boom"] = "boom"
+			return nil
+		})
+
+		err := Recover(zap.NewNop().Sugar())(handler).Handle(types.Message{})
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestLogging(t *testing.T) {
+	t.Run("Logging returns the result of the wrapped handler", func(t *testing.T) {
+		handlerErr := errors.New("test error")
+		handler := MessageHandlerFunc(func(message types.Message) error { return handlerErr })
+
+		err := Logging(zap.NewNop().Sugar())(handler).Handle(types.Message{})
+
+		assert.Equal(t, handlerErr, err)
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	t.Run("Timeout returns the handler result when it finishes in time", func(t *testing.T) {
+		handler := MessageHandlerFunc(func(message types.Message) error { return nil })
+
+		err := Timeout(time.Second)(handler).Handle(types.Message{})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("Timeout returns ErrHandlerTimeout when the handler takes too long", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		handler := MessageHandlerFunc(func(message types.Message) error {
+			<-release
+			return nil
+		})
+
+		err := Timeout(10 * time.Millisecond)(handler).Handle(types.Message{})
+
+		assert.ErrorIs(t, err, ErrHandlerTimeout)
+	})
+}
+
+func TestCorrelation(t *testing.T) {
+	t.Run("Correlation keeps the correlation ID sent with the message", func(t *testing.T) {
+		var received types.Message
+		handler := MessageHandlerFunc(func(message types.Message) error {
+			received = message
+			return nil
+		})
+		message := types.Message{MessageAttributes: map[string]types.MessageAttributeValue{
+			logger.CorrelationID: {DataType: aws.String("String"), StringValue: aws.String("test-id")},
+		}}
+
+		err := Correlation()(handler).Handle(message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "test-id", correlationIDFromMessage(received))
+	})
+
+	t.Run("Correlation generates a correlation ID when the message does not have one", func(t *testing.T) {
+		var received types.Message
+		handler := MessageHandlerFunc(func(message types.Message) error {
+			received = message
+			return nil
+		})
+		message := types.Message{MessageAttributes: map[string]types.MessageAttributeValue{
+			"other": {DataType: aws.String("String"), StringValue: aws.String("value")},
+		}}
+
+		err := Correlation()(handler).Handle(message)
+
+		assert.Nil(t, err)
+		assert.NotEmpty(t, correlationIDFromMessage(received))
+		assert.Contains(t, received.MessageAttributes, "other")
+		assert.NotContains(t, message.MessageAttributes, logger.CorrelationID)
+	})
+}
+
+func TestChainContext(t *testing.T) {
+	t.Run("ChainContext applies middlewares so that the first one is the outermost", func(t *testing.T) {
+		var calls []string
+		record := func(name string) ContextMiddleware {
+			return func(next ContextMessageHandler) ContextMessageHandler {
+				return ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+					calls = append(calls, name)
+					return next.Handle(ctx, message)
+				})
+			}
+		}
+		handler := ContextMessageHandlerFunc(func(_ context.Context, message types.Message) error {
+			calls = append(calls, "handler")
+			return nil
+		})
+
+		err := ChainContext(handler, record("first"), record("second")).Handle(context.Background(), types.Message{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"first", "second", "handler"}, calls)
+	})
+}
+
+func TestAdaptMiddleware(t *testing.T) {
+	t.Run("AdaptMiddleware passes the context and the message of the middleware to the next handler", func(t *testing.T) {
+		var received types.Message
+		var correlationID string
+		handler := ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+			received = message
+			correlationID, _ = correlation.FromContext(ctx)
+			return nil
+		})
+		ctx := correlation.WithId(context.Background(), "context-id")
+
+		err := AdaptMiddleware(Correlation())(handler).Handle(ctx, types.Message{})
+
+		assert.Nil(t, err)
+		assert.NotEmpty(t, correlationIDFromMessage(received))
+		assert.Equal(t, "context-id", correlationID)
+	})
+}
+
+func TestContextTimeout(t *testing.T) {
+	t.Run("ContextTimeout returns the handler result when it finishes in time", func(t *testing.T) {
+		handler := ContextMessageHandlerFunc(func(_ context.Context, message types.Message) error { return nil })
+
+		err := ContextTimeout(time.Second)(handler).Handle(context.Background(), types.Message{})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ContextTimeout cancels the context of the handler and returns ErrHandlerTimeout", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		handler := ContextMessageHandlerFunc(func(ctx context.Context, message types.Message) error {
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		})
+
+		err := ContextTimeout(10*time.Millisecond)(handler).Handle(context.Background(), types.Message{})
+
+		assert.ErrorIs(t, err, ErrHandlerTimeout)
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("handler context was not cancelled")
+		}
+	})
+}
+
+func TestContextTimeout_abandonedHandlers(t *testing.T) {
+	t.Run("ContextTimeout adds a handler that ignores its context to the abandoned handlers of the context", func(t *testing.T) {
+		release := make(chan struct{})
+		handler := ContextMessageHandlerFunc(func(context.Context, types.Message) error {
+			<-release
+			return nil
+		})
+		abandoned := new(abandonedHandlers)
+
+		err := ContextTimeout(10*time.Millisecond)(handler).Handle(withAbandonedHandlers(context.Background(), abandoned), types.Message{})
+
+		assert.ErrorIs(t, err, ErrHandlerTimeout)
+		waited := make(chan struct{})
+		go func() {
+			abandoned.wait(context.Background())
+			close(waited)
+		}()
+		select {
+		case <-waited:
+			t.Fatal("wait returned before the abandoned handler")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(release)
+		<-waited
+	})
+}
+
+func TestContextCorrelation(t *testing.T) {
+	t.Run("ContextCorrelation uses the correlation ID of the context when the message does not have one", func(t *testing.T) {
+		var received types.Message
+		handler := ContextMessageHandlerFunc(func(_ context.Context, message types.Message) error {
+			received = message
+			return nil
+		})
+
+		err := ContextCorrelation()(handler).Handle(correlation.WithId(context.Background(), "context-id"), types.Message{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "context-id", correlationIDFromMessage(received))
+	})
+
+	t.Run("ContextCorrelation keeps the correlation ID sent with the message", func(t *testing.T) {
+		var received types.Message
+		handler := ContextMessageHandlerFunc(func(_ context.Context, message types.Message) error {
+			received = message
+			return nil
+		})
+		message := types.Message{MessageAttributes: map[string]types.MessageAttributeValue{
+			logger.CorrelationID: {DataType: aws.String("String"), StringValue: aws.String("test-id")},
+		}}
+
+		err := ContextCorrelation()(handler).Handle(correlation.WithId(context.Background(), "context-id"), message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "test-id", correlationIDFromMessage(received))
+	})
+}
@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -33,14 +34,67 @@ func (m *MockISQSListener) EXPECT() *MockISQSListenerMockRecorder {
 	return m.recorder
 }
 
+// Drain mocks base method.
+func (m *MockISQSListener) Drain(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Drain", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Drain indicates an expected call of Drain.
+func (mr *MockISQSListenerMockRecorder) Drain(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Drain", reflect.TypeOf((*MockISQSListener)(nil).Drain), ctx)
+}
+
 // Listen mocks base method.
-func (m *MockISQSListener) Listen() {
+func (m *MockISQSListener) Listen(ctx context.Context) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Listen")
+	m.ctrl.Call(m, "Listen", ctx)
 }
 
 // Listen indicates an expected call of Listen.
-func (mr *MockISQSListenerMockRecorder) Listen() *gomock.Call {
+func (mr *MockISQSListenerMockRecorder) Listen(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockISQSListener)(nil).Listen), ctx)
+}
+
+// Pause mocks base method.
+func (m *MockISQSListener) Pause() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Pause")
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockISQSListenerMockRecorder) Pause() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockISQSListener)(nil).Pause))
+}
+
+// Resume mocks base method.
+func (m *MockISQSListener) Resume() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Resume")
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockISQSListenerMockRecorder) Resume() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockISQSListener)(nil).Resume))
+}
+
+// Run mocks base method.
+func (m *MockISQSListener) Run(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockISQSListenerMockRecorder) Run(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockISQSListener)(nil).Listen))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockISQSListener)(nil).Run), ctx)
 }
@@ -5,8 +5,10 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
+	zaws "github.com/ammyy9908/go-common-libraries/messaging"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,29 +36,79 @@ func (m *MockIQueuePublisher) EXPECT() *MockIQueuePublisherMockRecorder {
 }
 
 // Publish mocks base method.
-func (m *MockIQueuePublisher) Publish(message string) error {
+func (m *MockIQueuePublisher) Publish(message string, opts ...zaws.PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Publish", message)
+	varargs := []interface{}{message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Publish", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Publish indicates an expected call of Publish.
-func (mr *MockIQueuePublisherMockRecorder) Publish(message interface{}) *gomock.Call {
+func (mr *MockIQueuePublisherMockRecorder) Publish(message interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockIQueuePublisher)(nil).Publish), message)
+	varargs := append([]interface{}{message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockIQueuePublisher)(nil).Publish), varargs...)
+}
+
+// PublishCtx mocks base method.
+func (m *MockIQueuePublisher) PublishCtx(ctx context.Context, message string, opts ...zaws.PublishOption) (zaws.PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishCtx", varargs...)
+	ret0, _ := ret[0].(zaws.PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishCtx indicates an expected call of PublishCtx.
+func (mr *MockIQueuePublisherMockRecorder) PublishCtx(ctx, message interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishCtx", reflect.TypeOf((*MockIQueuePublisher)(nil).PublishCtx), varargs...)
 }
 
 // PublishWithAttributes mocks base method.
-func (m *MockIQueuePublisher) PublishWithAttributes(message string, attributes map[string]string) error {
+func (m *MockIQueuePublisher) PublishWithAttributes(message string, attributes map[string]string, opts ...zaws.PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishWithAttributes", message, attributes)
+	varargs := []interface{}{message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishWithAttributes", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PublishWithAttributes indicates an expected call of PublishWithAttributes.
-func (mr *MockIQueuePublisherMockRecorder) PublishWithAttributes(message, attributes interface{}) *gomock.Call {
+func (mr *MockIQueuePublisherMockRecorder) PublishWithAttributes(message, attributes interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributes", reflect.TypeOf((*MockIQueuePublisher)(nil).PublishWithAttributes), varargs...)
+}
+
+// PublishWithAttributesCtx mocks base method.
+func (m *MockIQueuePublisher) PublishWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...zaws.PublishOption) (zaws.PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishWithAttributesCtx", varargs...)
+	ret0, _ := ret[0].(zaws.PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishWithAttributesCtx indicates an expected call of PublishWithAttributesCtx.
+func (mr *MockIQueuePublisherMockRecorder) PublishWithAttributesCtx(ctx, message, attributes interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributes", reflect.TypeOf((*MockIQueuePublisher)(nil).PublishWithAttributes), message, attributes)
+	varargs := append([]interface{}{ctx, message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributesCtx", reflect.TypeOf((*MockIQueuePublisher)(nil).PublishWithAttributesCtx), varargs...)
 }
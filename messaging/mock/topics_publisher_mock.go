@@ -5,8 +5,10 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
+	zaws "github.com/ammyy9908/go-common-libraries/messaging"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,57 +36,157 @@ func (m *MockITopicsPublisher) EXPECT() *MockITopicsPublisherMockRecorder {
 }
 
 // Publish mocks base method.
-func (m *MockITopicsPublisher) Publish(topicName, message string) error {
+func (m *MockITopicsPublisher) Publish(topicName, message string, opts ...zaws.PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Publish", topicName, message)
+	varargs := []interface{}{topicName, message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Publish", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Publish indicates an expected call of Publish.
-func (mr *MockITopicsPublisherMockRecorder) Publish(topicName, message interface{}) *gomock.Call {
+func (mr *MockITopicsPublisherMockRecorder) Publish(topicName, message interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockITopicsPublisher)(nil).Publish), topicName, message)
+	varargs := append([]interface{}{topicName, message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockITopicsPublisher)(nil).Publish), varargs...)
+}
+
+// PublishCtx mocks base method.
+func (m *MockITopicsPublisher) PublishCtx(ctx context.Context, topicName, message string, opts ...zaws.PublishOption) (zaws.PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, topicName, message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishCtx", varargs...)
+	ret0, _ := ret[0].(zaws.PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishCtx indicates an expected call of PublishCtx.
+func (mr *MockITopicsPublisherMockRecorder) PublishCtx(ctx, topicName, message interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, topicName, message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishCtx", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishCtx), varargs...)
 }
 
 // PublishEvent mocks base method.
-func (m *MockITopicsPublisher) PublishEvent(topicName, subject, message string) error {
+func (m *MockITopicsPublisher) PublishEvent(topicName, subject, message string, opts ...zaws.PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishEvent", topicName, subject, message)
+	varargs := []interface{}{topicName, subject, message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishEvent", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PublishEvent indicates an expected call of PublishEvent.
-func (mr *MockITopicsPublisherMockRecorder) PublishEvent(topicName, subject, message interface{}) *gomock.Call {
+func (mr *MockITopicsPublisherMockRecorder) PublishEvent(topicName, subject, message interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{topicName, subject, message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEvent", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishEvent), varargs...)
+}
+
+// PublishEventCtx mocks base method.
+func (m *MockITopicsPublisher) PublishEventCtx(ctx context.Context, topicName, subject, message string, opts ...zaws.PublishOption) (zaws.PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, topicName, subject, message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishEventCtx", varargs...)
+	ret0, _ := ret[0].(zaws.PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishEventCtx indicates an expected call of PublishEventCtx.
+func (mr *MockITopicsPublisherMockRecorder) PublishEventCtx(ctx, topicName, subject, message interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEvent", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishEvent), topicName, subject, message)
+	varargs := append([]interface{}{ctx, topicName, subject, message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEventCtx", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishEventCtx), varargs...)
 }
 
 // PublishEventWithAttributes mocks base method.
-func (m *MockITopicsPublisher) PublishEventWithAttributes(topicName, subject, message string, attributes map[string]string) error {
+func (m *MockITopicsPublisher) PublishEventWithAttributes(topicName, subject, message string, attributes map[string]string, opts ...zaws.PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishEventWithAttributes", topicName, subject, message, attributes)
+	varargs := []interface{}{topicName, subject, message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishEventWithAttributes", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PublishEventWithAttributes indicates an expected call of PublishEventWithAttributes.
-func (mr *MockITopicsPublisherMockRecorder) PublishEventWithAttributes(topicName, subject, message, attributes interface{}) *gomock.Call {
+func (mr *MockITopicsPublisherMockRecorder) PublishEventWithAttributes(topicName, subject, message, attributes interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEventWithAttributes", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishEventWithAttributes), topicName, subject, message, attributes)
+	varargs := append([]interface{}{topicName, subject, message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEventWithAttributes", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishEventWithAttributes), varargs...)
+}
+
+// PublishEventWithAttributesCtx mocks base method.
+func (m *MockITopicsPublisher) PublishEventWithAttributesCtx(ctx context.Context, topicName, subject, message string, attributes map[string]string, opts ...zaws.PublishOption) (zaws.PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, topicName, subject, message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishEventWithAttributesCtx", varargs...)
+	ret0, _ := ret[0].(zaws.PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishEventWithAttributesCtx indicates an expected call of PublishEventWithAttributesCtx.
+func (mr *MockITopicsPublisherMockRecorder) PublishEventWithAttributesCtx(ctx, topicName, subject, message, attributes interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, topicName, subject, message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEventWithAttributesCtx", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishEventWithAttributesCtx), varargs...)
 }
 
 // PublishWithAttributes mocks base method.
-func (m *MockITopicsPublisher) PublishWithAttributes(topicName, message string, attributes map[string]string) error {
+func (m *MockITopicsPublisher) PublishWithAttributes(topicName, message string, attributes map[string]string, opts ...zaws.PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishWithAttributes", topicName, message, attributes)
+	varargs := []interface{}{topicName, message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishWithAttributes", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PublishWithAttributes indicates an expected call of PublishWithAttributes.
-func (mr *MockITopicsPublisherMockRecorder) PublishWithAttributes(topicName, message, attributes interface{}) *gomock.Call {
+func (mr *MockITopicsPublisherMockRecorder) PublishWithAttributes(topicName, message, attributes interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{topicName, message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributes", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishWithAttributes), varargs...)
+}
+
+// PublishWithAttributesCtx mocks base method.
+func (m *MockITopicsPublisher) PublishWithAttributesCtx(ctx context.Context, topicName, message string, attributes map[string]string, opts ...zaws.PublishOption) (zaws.PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, topicName, message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishWithAttributesCtx", varargs...)
+	ret0, _ := ret[0].(zaws.PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishWithAttributesCtx indicates an expected call of PublishWithAttributesCtx.
+func (mr *MockITopicsPublisherMockRecorder) PublishWithAttributesCtx(ctx, topicName, message, attributes interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributes", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishWithAttributes), topicName, message, attributes)
+	varargs := append([]interface{}{ctx, topicName, message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributesCtx", reflect.TypeOf((*MockITopicsPublisher)(nil).PublishWithAttributesCtx), varargs...)
 }
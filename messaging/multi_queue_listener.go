@@ -0,0 +1,323 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ammyy9908/go-common-libraries/gracefulshutdown"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultIdleWait        = time.Second
+	errNoQueues            = "multi queue listener needs at least one queue"
+	errDuplicateQueueName  = "queue is subscribed more than once"
+	errNegativeQueueWeight = "queue weight cannot be negative"
+)
+
+// QueueSubscription is one of the queues consumed by a MultiQueueListener.
+type QueueSubscription struct {
+	QueueName string
+	Handler   MessageHandler
+	// ContextHandler is used instead of Handler when set, see ListenerConfig.
+	ContextHandler ContextMessageHandler
+	// Priority orders the queues strictly: a queue is only polled when every queue with a higher priority was empty.
+	Priority int
+	// Weight decides how often a queue is polled first among the queues with the same priority. Defaults to 1.
+	Weight int
+}
+
+type MultiQueueListenerConfig struct {
+	Logger *zap.SugaredLogger
+	Queues []QueueSubscription
+	// Middlewares and ContextMiddlewares wrap the handler of every queue, see ListenerConfig.
+	Middlewares        []Middleware
+	ContextMiddlewares []ContextMiddleware
+	// GracefulShutdownManager is optional, see ListenerConfig.
+	GracefulShutdownManager *gracefulshutdown.Manager
+	MaxNumberOfMessages     int
+	// MaxConcurrency limits how many messages of all queues are handled at the same time. Defaults to
+	// DefaultMaxConcurrency.
+	MaxConcurrency int
+	// DrainTimeout is how long in-flight handlers may keep running after the listener stopped, see ListenerConfig.
+	DrainTimeout time.Duration
+	// ReceiveMessageWaitSeconds long polls the last queue of every round, see ListenerConfig. Zero short polls every
+	// queue.
+	ReceiveMessageWaitSeconds int
+	// IdleWait is how long the listener waits after every queue was empty before polling again. It is skipped when
+	// the last queue was long polled. Defaults to DefaultIdleWait.
+	IdleWait time.Duration
+}
+
+// MultiQueueListener consumes several queues with one shared worker pool. Queues are polled in priority order and
+// the first queue that returns messages is handled before polling starts again from the top. Every queue is short
+// polled except the last one of a round, which is long polled for ReceiveMessageWaitSeconds so that an idle
+// listener waits on SQS instead of sending empty receives.
+type MultiQueueListener struct {
+	logger                    *zap.SugaredLogger
+	queues                    []*subscribedQueue
+	gracefulShutdownManager   *gracefulshutdown.Manager
+	maxNumberOfMessages       int
+	maxConcurrency            int
+	drainTimeout              time.Duration
+	receiveMessageWaitSeconds int
+	idleWait                  time.Duration
+}
+
+type subscribedQueue struct {
+	listener      *SQSListener
+	priority      int
+	weight        int
+	currentWeight int
+	retryAt       time.Time
+}
+
+func NewMultiQueueListener(region string, config MultiQueueListenerConfig) (*MultiQueueListener, error) {
+	cfg, err := awsConfig.LoadDefaultConfig(context.Background(), awsConfig.WithRegion(region))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMultiQueueListenerWithConfig(cfg, config)
+}
+
+func NewMultiQueueListenerWithConfig(cfg aws.Config, config MultiQueueListenerConfig) (*MultiQueueListener, error) {
+	err := validateQueueSubscriptions(config.Queues)
+	if err != nil {
+		return nil, err
+	}
+
+	queues := make([]*subscribedQueue, 0, len(config.Queues))
+	for _, subscription := range config.Queues {
+		listener, err := NewListenerWithConfig(subscription.QueueName, cfg, ListenerConfig{
+			Logger:              config.Logger,
+			Handler:             subscription.Handler,
+			ContextHandler:      subscription.ContextHandler,
+			Middlewares:         config.Middlewares,
+			ContextMiddlewares:  config.ContextMiddlewares,
+			MaxNumberOfMessages: config.MaxNumberOfMessages,
+		})
+		if err != nil {
+			return nil, err
+		}
+		queues = append(queues, newSubscribedQueue(listener, subscription))
+	}
+
+	return &MultiQueueListener{
+		logger:                    config.Logger,
+		queues:                    queues,
+		gracefulShutdownManager:   config.GracefulShutdownManager,
+		maxNumberOfMessages:       config.MaxNumberOfMessages,
+		maxConcurrency:            config.MaxConcurrency,
+		drainTimeout:              config.DrainTimeout,
+		receiveMessageWaitSeconds: config.ReceiveMessageWaitSeconds,
+		idleWait:                  config.IdleWait,
+	}, nil
+}
+
+func newSubscribedQueue(listener *SQSListener, subscription QueueSubscription) *subscribedQueue {
+	weight := subscription.Weight
+	if weight == 0 {
+		weight = 1
+	}
+	return &subscribedQueue{
+		listener: listener,
+		priority: subscription.Priority,
+		weight:   weight,
+	}
+}
+
+func validateQueueSubscriptions(subscriptions []QueueSubscription) error {
+	if len(subscriptions) == 0 {
+		return errors.New(errNoQueues)
+	}
+
+	seen := make(map[string]bool, len(subscriptions))
+	for _, subscription := range subscriptions {
+		if seen[subscription.QueueName] {
+			return fmt.Errorf("%s: %s", errDuplicateQueueName, subscription.QueueName)
+		}
+		seen[subscription.QueueName] = true
+		if subscription.Weight < 0 {
+			return fmt.Errorf("%s: %s", errNegativeQueueWeight, subscription.QueueName)
+		}
+	}
+	return nil
+}
+
+// Listen runs the listener until ctx is cancelled or a graceful shutdown is requested and logs why it stopped.
+func (m *MultiQueueListener) Listen(ctx context.Context) {
+	err := m.Run(ctx)
+	m.logger.Info(err.Error())
+}
+
+// Run polls the queues until ctx is cancelled or a graceful shutdown is requested. It waits for in-flight messages
+// to be handled and returns an error wrapping the reason it stopped.
+func (m *MultiQueueListener) Run(ctx context.Context) error {
+	if m.gracefulShutdownManager != nil {
+		m.gracefulShutdownManager.ShutdownWaitGroup.Add(1)
+		defer m.gracefulShutdownManager.ShutdownWaitGroup.Done()
+	}
+
+	ctx, stopReason := withShutdown(ctx, m.gracefulShutdownManager)
+
+	workers := newWorkerPool(m.concurrency())
+	stopped := make(chan struct{})
+	for _, queue := range m.queues {
+		queue.listener.start(workers)
+		go cancelAfterDrainTimeout(ctx, m.drainTimeout, stopped, queue.listener.cancelHandlers)
+	}
+	m.poll(ctx, workers)
+	workers.stop()
+	close(stopped)
+	for _, queue := range m.queues {
+		queue.listener.stop()
+	}
+
+	return fmt.Errorf("multi queue listener stopped: %w", stopReason())
+}
+
+// Stats returns the stats of every queue by queue name.
+func (m *MultiQueueListener) Stats() map[string]ListenerStats {
+	stats := make(map[string]ListenerStats, len(m.queues))
+	for _, queue := range m.queues {
+		stats[queue.listener.queueName] = queue.listener.Stats()
+	}
+	return stats
+}
+
+func (m *MultiQueueListener) poll(ctx context.Context, workers *workerPool) {
+	for {
+		reserved, err := workers.reserve(ctx, m.batchSize())
+		if err != nil {
+			return
+		}
+
+		received, longPolled := m.receive(ctx, reserved)
+		if received {
+			continue
+		}
+		workers.release(reserved)
+		if longPolled {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(m.idle()):
+		}
+	}
+}
+
+// receive polls the queues in order until one of them returns messages and dispatches those messages. Only the last
+// queue that is not backing off is long polled. It returns false when every queue was empty, failed or is backing
+// off after a failure, and reports whether an empty long poll already waited for messages.
+func (m *MultiQueueListener) receive(ctx context.Context, reserved int) (received bool, longPolled bool) {
+	now := time.Now()
+	eligible := make([]*subscribedQueue, 0, len(m.queues))
+	for _, queue := range m.pollOrder() {
+		if !now.Before(queue.retryAt) {
+			eligible = append(eligible, queue)
+		}
+	}
+
+	for i, queue := range eligible {
+		l := queue.listener
+		request := l.receiveRequest()
+		request.WaitTimeSeconds = 0
+		if i == len(eligible)-1 {
+			request.WaitTimeSeconds = int32(m.receiveMessageWaitSeconds)
+		}
+		request.MaxNumberOfMessages = int32(reserved)
+		result, err := l.sqsClient.ReceiveMessage(ctx, request)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, false
+			}
+			failures := l.receiveFailures.Add(1)
+			queue.retryAt = time.Now().Add(l.receiveBackoff(failures))
+			l.logger.Warnw("failed to receive messages", "queue", l.queueName, "failures", failures, "error", err)
+			continue
+		}
+		l.receiveSucceeded()
+
+		if len(result.Messages) > 0 {
+			l.dispatch(result.Messages, reserved)
+			return true, false
+		}
+		longPolled = request.WaitTimeSeconds > 0
+	}
+	return false, longPolled
+}
+
+// pollOrder sorts the queues by descending priority. Within a priority the queue picked by smooth weighted round
+// robin comes first, followed by the others by descending weight.
+func (m *MultiQueueListener) pollOrder() []*subscribedQueue {
+	order := make([]*subscribedQueue, len(m.queues))
+	copy(order, m.queues)
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].priority != order[j].priority {
+			return order[i].priority > order[j].priority
+		}
+		return order[i].weight > order[j].weight
+	})
+
+	for start := 0; start < len(order); {
+		end := start
+		for end < len(order) && order[end].priority == order[start].priority {
+			end++
+		}
+		pickFirst(order[start:end])
+		start = end
+	}
+	return order
+}
+
+// pickFirst moves the next queue of the smooth weighted round robin to the front of queues.
+func pickFirst(queues []*subscribedQueue) {
+	if len(queues) < 2 {
+		return
+	}
+
+	total, best := 0, 0
+	for i, queue := range queues {
+		queue.currentWeight += queue.weight
+		total += queue.weight
+		if queue.currentWeight > queues[best].currentWeight {
+			best = i
+		}
+	}
+	queues[best].currentWeight -= total
+
+	first := queues[best]
+	copy(queues[1:best+1], queues[:best])
+	queues[0] = first
+}
+
+func (m *MultiQueueListener) concurrency() int {
+	if m.maxConcurrency <= 0 {
+		return DefaultMaxConcurrency
+	}
+	return m.maxConcurrency
+}
+
+func (m *MultiQueueListener) batchSize() int {
+	if m.maxNumberOfMessages <= 0 {
+		return 1
+	}
+	return m.maxNumberOfMessages
+}
+
+func (m *MultiQueueListener) idle() time.Duration {
+	if m.idleWait <= 0 {
+		return DefaultIdleWait
+	}
+	return m.idleWait
+}
@@ -0,0 +1,221 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestSubscribedQueue(sqsClient ISQSClient, handler MessageHandler, queueName string, priority, weight int) *subscribedQueue {
+	listener := getTestListener()
+	listener.queueName = queueName
+	listener.queueURL = aws.String(queueName + ".com")
+	listener.sqsClient = sqsClient
+	listener.handler = handler
+	listener.gracefulShutdownManager = nil
+	return newSubscribedQueue(listener, QueueSubscription{QueueName: queueName, Priority: priority, Weight: weight})
+}
+
+func queueNames(queues []*subscribedQueue) []string {
+	names := make([]string, 0, len(queues))
+	for _, queue := range queues {
+		names = append(names, queue.listener.queueName)
+	}
+	return names
+}
+
+func Test_validateQueueSubscriptions(t *testing.T) {
+	t.Run("validateQueueSubscriptions returns an error without queues", func(t *testing.T) {
+		assert.EqualError(t, validateQueueSubscriptions(nil), errNoQueues)
+	})
+
+	t.Run("validateQueueSubscriptions returns an error when a queue is subscribed twice", func(t *testing.T) {
+		err := validateQueueSubscriptions([]QueueSubscription{{QueueName: "q"}, {QueueName: "q"}})
+
+		assert.EqualError(t, err, errDuplicateQueueName+": q")
+	})
+
+	t.Run("validateQueueSubscriptions returns an error for a negative weight", func(t *testing.T) {
+		err := validateQueueSubscriptions([]QueueSubscription{{QueueName: "q", Weight: -1}})
+
+		assert.EqualError(t, err, errNegativeQueueWeight+": q")
+	})
+}
+
+func TestMultiQueueListener_pollOrder(t *testing.T) {
+	t.Run("pollOrder polls higher priorities first", func(t *testing.T) {
+		sut := &MultiQueueListener{queues: []*subscribedQueue{
+			getTestSubscribedQueue(nil, nil, "low", 0, 1),
+			getTestSubscribedQueue(nil, nil, "high", 10, 1),
+			getTestSubscribedQueue(nil, nil, "medium", 5, 1),
+		}}
+
+		assert.Equal(t, []string{"high", "medium", "low"}, queueNames(sut.pollOrder()))
+	})
+
+	t.Run("pollOrder puts queues of the same priority first in proportion to their weight", func(t *testing.T) {
+		sut := &MultiQueueListener{queues: []*subscribedQueue{
+			getTestSubscribedQueue(nil, nil, "light", 0, 1),
+			getTestSubscribedQueue(nil, nil, "heavy", 0, 3),
+		}}
+
+		firsts := map[string]int{}
+		for i := 0; i < 8; i++ {
+			firsts[sut.pollOrder()[0].listener.queueName]++
+		}
+
+		assert.Equal(t, map[string]int{"heavy": 6, "light": 2}, firsts)
+	})
+}
+
+func TestMultiQueueListener_receive(t *testing.T) {
+	t.Run("receive polls the lower priority queue when the higher priority queue is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqsClient := NewMockISQSClient(ctrl)
+		highHandler := NewMockMessageHandler(ctrl)
+		lowHandler := NewMockMessageHandler(ctrl)
+		high := getTestSubscribedQueue(sqsClient, highHandler, "high", 1, 1)
+		low := getTestSubscribedQueue(sqsClient, lowHandler, "low", 0, 1)
+		sut := &MultiQueueListener{queues: []*subscribedQueue{low, high}}
+		workers := newWorkerPool(1)
+		high.listener.start(workers)
+		low.listener.start(workers)
+		message := types.Message{MessageId: aws.String("low-1"), ReceiptHandle: aws.String("low handle")}
+
+		gomock.InOrder(
+			sqsClient.
+				EXPECT().
+				ReceiveMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, input *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+					assert.Equal(t, "high.com", aws.ToString(input.QueueUrl))
+					assert.Equal(t, int32(0), input.WaitTimeSeconds)
+					return &sqs.ReceiveMessageOutput{}, nil
+				}),
+			sqsClient.
+				EXPECT().
+				ReceiveMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, input *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+					assert.Equal(t, "low.com", aws.ToString(input.QueueUrl))
+					return &sqs.ReceiveMessageOutput{Messages: []types.Message{message}}, nil
+				}),
+		)
+		lowHandler.EXPECT().Handle(message).Return(nil)
+		sqsClient.
+			EXPECT().
+			DeleteMessage(gomock.Any(), &sqs.DeleteMessageInput{QueueUrl: aws.String("low.com"), ReceiptHandle: aws.String("low handle")}).
+			Return(&sqs.DeleteMessageOutput{}, nil)
+
+		reserved, _ := workers.reserve(context.Background(), 1)
+		got, _ := sut.receive(context.Background(), reserved)
+		workers.stop()
+
+		assert.True(t, got)
+		stats := sut.Stats()
+		assert.Equal(t, int64(0), stats["high"].Received)
+		assert.Equal(t, int64(1), stats["low"].Received)
+		assert.Equal(t, int64(1), stats["low"].Succeeded)
+	})
+
+	t.Run("receive skips a failed queue until its backoff has passed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqsClient := NewMockISQSClient(ctrl)
+		failing := getTestSubscribedQueue(sqsClient, nil, "failing", 1, 1)
+		failing.listener.receiveBackoffBase = time.Hour
+		empty := getTestSubscribedQueue(sqsClient, nil, "empty", 0, 1)
+		sut := &MultiQueueListener{queues: []*subscribedQueue{failing, empty}}
+
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				if aws.ToString(input.QueueUrl) == "failing.com" {
+					return nil, errors.New("receive error")
+				}
+				return &sqs.ReceiveMessageOutput{}, nil
+			}).
+			Times(3)
+
+		got, _ := sut.receive(context.Background(), 1)
+		assert.False(t, got)
+		got, _ = sut.receive(context.Background(), 1)
+		assert.False(t, got)
+	})
+
+	t.Run("receive long polls only the last queue of the round", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqsClient := NewMockISQSClient(ctrl)
+		high := getTestSubscribedQueue(sqsClient, nil, "high", 1, 1)
+		low := getTestSubscribedQueue(sqsClient, nil, "low", 0, 1)
+		sut := &MultiQueueListener{queues: []*subscribedQueue{low, high}, receiveMessageWaitSeconds: 20}
+
+		waits := map[string]int32{}
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				waits[aws.ToString(input.QueueUrl)] = input.WaitTimeSeconds
+				return &sqs.ReceiveMessageOutput{}, nil
+			}).
+			Times(2)
+
+		got, longPolled := sut.receive(context.Background(), 1)
+
+		assert.False(t, got)
+		assert.True(t, longPolled)
+		assert.Equal(t, map[string]int32{"high.com": 0, "low.com": 20}, waits)
+	})
+
+	t.Run("receive long polls the only queue that is not backing off", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sqsClient := NewMockISQSClient(ctrl)
+		high := getTestSubscribedQueue(sqsClient, nil, "high", 1, 1)
+		low := getTestSubscribedQueue(sqsClient, nil, "low", 0, 1)
+		low.retryAt = time.Now().Add(time.Hour)
+		sut := &MultiQueueListener{queues: []*subscribedQueue{low, high}, receiveMessageWaitSeconds: 20}
+
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				assert.Equal(t, "high.com", aws.ToString(input.QueueUrl))
+				assert.Equal(t, int32(20), input.WaitTimeSeconds)
+				return &sqs.ReceiveMessageOutput{}, nil
+			})
+
+		got, longPolled := sut.receive(context.Background(), 1)
+
+		assert.False(t, got)
+		assert.True(t, longPolled)
+	})
+}
+
+func TestMultiQueueListener_Run(t *testing.T) {
+	t.Run("Run returns the context error after the context is cancelled", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut := &MultiQueueListener{
+			logger:   getTestListener().logger,
+			queues:   []*subscribedQueue{getTestSubscribedQueue(sqsClient, nil, "q", 0, 1)},
+			idleWait: time.Millisecond,
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, *sqs.ReceiveMessageInput, ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				cancel()
+				return &sqs.ReceiveMessageOutput{}, nil
+			})
+
+		err := sut.Run(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -1,7 +1,9 @@
 package zaws
 
 import (
+	"context"
 	"fmt"
+
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/goccy/go-json"
 )
@@ -13,15 +15,24 @@ type Event struct {
 
 type IMultiTopicHandler interface {
 	RegisterHandler(subject string, handlerFunc EventHandler)
+	RegisterContextHandler(subject string, handlerFunc ContextEventHandler)
 	Handle(message types.Message) error
+	HandleContext(ctx context.Context, message types.Message) error
 }
 
 type MultiTopicHandler struct {
 	Handlers map[string]EventHandler
+	// BlobStore resolves claim check messages published with ClaimCheckConfig. Blobs are not deleted since every
+	// subscriber of the topic reads them; expire them with a lifecycle rule of the store instead.
+	BlobStore       BlobStore
+	contextHandlers map[string]ContextEventHandler
 }
 
 type EventHandler func(message string) error
 
+// ContextEventHandler is an EventHandler that receives the handler context, see HandleContext.
+type ContextEventHandler func(ctx context.Context, message string) error
+
 func NewMultiTopicHandler() *MultiTopicHandler {
 	h := make(map[string]EventHandler)
 	return &MultiTopicHandler{Handlers: h}
@@ -31,20 +42,49 @@ func (h *MultiTopicHandler) RegisterHandler(subject string, handlerFunc EventHan
 	h.Handlers[subject] = handlerFunc
 }
 
+// RegisterContextHandler registers a handler for subject that receives the context passed to HandleContext. It
+// takes precedence over a handler registered with RegisterHandler for the same subject.
+func (h *MultiTopicHandler) RegisterContextHandler(subject string, handlerFunc ContextEventHandler) {
+	if h.contextHandlers == nil {
+		h.contextHandlers = make(map[string]ContextEventHandler)
+	}
+	h.contextHandlers[subject] = handlerFunc
+}
+
 func (h *MultiTopicHandler) Handle(message types.Message) error {
+	return h.HandleContext(context.Background(), message)
+}
+
+// HandleContext is Handle with a context for the handlers registered with RegisterContextHandler. Use
+// ContextMessageHandlerFunc(h.HandleContext) as the ContextHandler of a listener.
+func (h *MultiTopicHandler) HandleContext(ctx context.Context, message types.Message) error {
 	var event Event
 	b := []byte(*message.Body)
 	fmt.Println(string(b))
 	err := json.Unmarshal(b, &event)
 	if err != nil {
 		fmt.Println(message.Body)
-		return err
+		return Permanent(err)
 	}
-	handler, ok := h.Handlers[event.Subject]
+	handler, ok := h.contextHandlers[event.Subject]
 	if !ok {
-		return fmt.Errorf("no handler for Subject: %s", event.Subject)
+		eventHandler, ok := h.Handlers[event.Subject]
+		if !ok {
+			return Permanent(fmt.Errorf("no handler for Subject: %s", event.Subject))
+		}
+		handler = func(_ context.Context, message string) error { return eventHandler(message) }
+	}
+
+	attributes, err := DecodeAttributes(message)
+	if err != nil {
+		return Permanent(err)
+	}
+	_, marked := attributes[ClaimCheckAttribute]
+	eventMessage, _, err := resolveClaimCheck(ctx, h.BlobStore, marked, event.Message)
+	if err != nil {
+		return err
 	}
 
-	err = handler(event.Message)
+	err = handler(ctx, eventMessage)
 	return err
 }
@@ -5,6 +5,7 @@
 package zaws
 
 import (
+	context "context"
 	reflect "reflect"
 
 	types "github.com/aws/aws-sdk-go-v2/service/sqs/types"
@@ -48,6 +49,32 @@ func (mr *MockIMultiTopicHandlerMockRecorder) Handle(message interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockIMultiTopicHandler)(nil).Handle), message)
 }
 
+// HandleContext mocks base method.
+func (m *MockIMultiTopicHandler) HandleContext(ctx context.Context, message types.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleContext", ctx, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleContext indicates an expected call of HandleContext.
+func (mr *MockIMultiTopicHandlerMockRecorder) HandleContext(ctx, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleContext", reflect.TypeOf((*MockIMultiTopicHandler)(nil).HandleContext), ctx, message)
+}
+
+// RegisterContextHandler mocks base method.
+func (m *MockIMultiTopicHandler) RegisterContextHandler(subject string, handlerFunc ContextEventHandler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegisterContextHandler", subject, handlerFunc)
+}
+
+// RegisterContextHandler indicates an expected call of RegisterContextHandler.
+func (mr *MockIMultiTopicHandlerMockRecorder) RegisterContextHandler(subject, handlerFunc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterContextHandler", reflect.TypeOf((*MockIMultiTopicHandler)(nil).RegisterContextHandler), subject, handlerFunc)
+}
+
 // RegisterHandler mocks base method.
 func (m *MockIMultiTopicHandler) RegisterHandler(subject string, handlerFunc EventHandler) {
 	m.ctrl.T.Helper()
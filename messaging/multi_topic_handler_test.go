@@ -1,6 +1,7 @@
 package zaws
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -44,6 +45,7 @@ func TestMultiTopicHandler_Handle(t *testing.T) {
 		err := mtH.Handle(message)
 
 		assert.NotNil(t, err)
+		assert.True(t, IsPermanent(err))
 	})
 
 	t.Run("MultiTopicHandler Handle takes in a message, fetches returns an error if the message does not have correct format", func(t *testing.T) {
@@ -52,6 +54,7 @@ func TestMultiTopicHandler_Handle(t *testing.T) {
 		err := mtH.Handle(message)
 
 		assert.NotNil(t, err)
+		assert.True(t, IsPermanent(err))
 	})
 
 	t.Run("MultiTopicHandler Handle takes in a message, returns an error if the message handler returns an error", func(t *testing.T) {
@@ -65,3 +68,24 @@ func TestMultiTopicHandler_Handle(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestMultiTopicHandler_RegisterContextHandler(t *testing.T) {
+	t.Run("RegisterContextHandler takes precedence over a handler registered with RegisterHandler", func(t *testing.T) {
+		mtH := NewMultiTopicHandler()
+		var handledBy string
+		mtH.RegisterHandler("test-topic", func(string) error {
+			handledBy = "handler"
+			return nil
+		})
+		mtH.RegisterContextHandler("test-topic", func(context.Context, string) error {
+			handledBy = "context handler"
+			return nil
+		})
+		message := types.Message{Body: aws.String(`{"subject":"test-topic","message":"test message"}`)}
+
+		err := mtH.Handle(message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "context handler", handledBy)
+	})
+}
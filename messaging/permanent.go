@@ -0,0 +1,158 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/goccy/go-json"
+)
+
+const (
+	// FailureAttribute carries the Failure of a dead letter as JSON.
+	FailureAttribute       = "X-Failure"
+	maxFailureReasonLength = 1024
+	// maxMessageAttributes is how many message attributes SQS accepts per message.
+	maxMessageAttributes = 10
+)
+
+// Failure describes why the listener moved a message to the dead-letter queue.
+type Failure struct {
+	Reason            string `json:"reason"`
+	OriginalMessageID string `json:"originalMessageId"`
+}
+
+// DecodeFailure returns the Failure the listener attached to a dead letter. ok is false when the message has none.
+func DecodeFailure(message types.Message) (failure Failure, ok bool) {
+	attribute, found := message.MessageAttributes[FailureAttribute]
+	if !found {
+		return Failure{}, false
+	}
+	err := json.Unmarshal([]byte(aws.ToString(attribute.StringValue)), &failure)
+	return failure, err == nil
+}
+
+// PermanentError marks a failure that retrying cannot fix. The listener moves such messages to the dead-letter
+// queue right away instead of waiting for MaxReceiveCount redeliveries.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return "permanent failure: " + e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so that the listener sends the message straight to the dead-letter queue.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+// deadLetter forwards a permanently failed message to the dead-letter queue with its original attributes and a
+// FailureAttribute, then deletes the original. The FailureAttribute is left out when the message already has as many
+// attributes as SQS accepts. The message is left on the queue when forwarding fails, which
+// deadLetter reports by returning false.
+func (l *SQSListener) deadLetter(message types.Message, handlerErr error) bool {
+	ctx := context.Background()
+	dlqURL, err := l.deadLetterQueueURL(ctx)
+	if err != nil {
+		l.logger.Errorw("failed to resolve dead-letter queue", "queue", l.queueName, "error", err)
+		return false
+	}
+
+	reason := handlerErr.Error()
+	if len(reason) > maxFailureReasonLength {
+		reason = reason[:maxFailureReasonLength]
+	}
+	attributes := make(map[string]types.MessageAttributeValue, len(message.MessageAttributes)+1)
+	for key, value := range message.MessageAttributes {
+		attributes[key] = value
+	}
+	_, hasFailure := attributes[FailureAttribute]
+	if hasFailure || len(attributes) < maxMessageAttributes {
+		failure, _ := json.Marshal(Failure{Reason: reason, OriginalMessageID: aws.ToString(message.MessageId)})
+		attributes[FailureAttribute] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(string(failure))}
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(dlqURL),
+		MessageBody:       message.Body,
+		MessageAttributes: attributes,
+	}
+	if IsFifo(dlqURL) {
+		input.MessageGroupId = aws.String(messageGroupID(message))
+		input.MessageDeduplicationId = message.MessageId
+	}
+	_, err = l.sqsClient.SendMessage(ctx, input)
+	if err != nil {
+		l.logger.Errorw("failed to move message to dead-letter queue",
+			"messageId", aws.ToString(message.MessageId),
+			"error", err,
+		)
+		return false
+	}
+
+	l.logger.Warnw("moved permanently failed message to dead-letter queue",
+		"messageId", aws.ToString(message.MessageId),
+		"reason", reason,
+	)
+	l.ack(message, nil)
+	return true
+}
+
+// deadLetterQueueURL returns the dead-letter queue from the RedrivePolicy of the queue, falling back to the
+// ErrorQueueSuffix naming convention. The result is cached.
+func (l *SQSListener) deadLetterQueueURL(ctx context.Context) (string, error) {
+	l.dlqMu.Lock()
+	defer l.dlqMu.Unlock()
+	if l.dlqURL != "" {
+		return l.dlqURL, nil
+	}
+
+	dlqName := deadLetterQueueName(l.queueName)
+	if name, ok := redrivePolicyTarget(ctx, l.sqsClient, l.queueURL); ok {
+		dlqName = name
+	}
+
+	dlqURL, err := GetQueueURL(l.sqsClient, dlqName)
+	if err != nil {
+		return "", err
+	}
+	l.dlqURL = dlqURL
+	return dlqURL, nil
+}
+
+// redrivePolicyTarget returns the name of the dead-letter queue in the RedrivePolicy of a queue.
+func redrivePolicyTarget(ctx context.Context, sqsClient ISQSClient, queueURL *string) (string, bool) {
+	result, err := sqsClient.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameRedrivePolicy},
+		QueueUrl:       queueURL,
+	})
+	if err != nil {
+		return "", false
+	}
+
+	var policy struct {
+		DeadLetterTargetArn string `json:"deadLetterTargetArn"`
+	}
+	err = json.Unmarshal([]byte(result.Attributes[string(types.QueueAttributeNameRedrivePolicy)]), &policy)
+	if err != nil || policy.DeadLetterTargetArn == "" {
+		return "", false
+	}
+
+	return policy.DeadLetterTargetArn[strings.LastIndex(policy.DeadLetterTargetArn, ":")+1:], true
+}
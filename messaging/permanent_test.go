@@ -0,0 +1,181 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermanent(t *testing.T) {
+	t.Run("Permanent returns nil for a nil error", func(t *testing.T) {
+		assert.Nil(t, Permanent(nil))
+	})
+
+	t.Run("IsPermanent detects a wrapped permanent error and unwraps to its cause", func(t *testing.T) {
+		cause := errors.New("malformed message")
+
+		err := fmt.Errorf("handler: %w", Permanent(cause))
+
+		assert.True(t, IsPermanent(err))
+		assert.ErrorIs(t, err, cause)
+	})
+
+	t.Run("IsPermanent returns false for other errors", func(t *testing.T) {
+		assert.False(t, IsPermanent(errors.New("temporary")))
+	})
+}
+
+func TestSQSListener_deadLetterQueueURL(t *testing.T) {
+	t.Run("deadLetterQueueURL resolves the dead-letter queue from the redrive policy and caches it", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			GetQueueAttributes(gomock.Any(), &sqs.GetQueueAttributesInput{
+				AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameRedrivePolicy},
+				QueueUrl:       sut.queueURL,
+			}).
+			Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{
+				"RedrivePolicy": `{"deadLetterTargetArn":"arn:aws:sqs:ap-south-1:000000000000:custom-dlq","maxReceiveCount":"5"}`,
+			}}, nil).
+			Times(1)
+		sqsClient.
+			EXPECT().
+			GetQueueUrl(gomock.Any(), &sqs.GetQueueUrlInput{QueueName: aws.String("custom-dlq")}).
+			Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("custom-dlq.com")}, nil).
+			Times(1)
+
+		first, err := sut.deadLetterQueueURL(context.Background())
+		assert.Nil(t, err)
+		second, err := sut.deadLetterQueueURL(context.Background())
+		assert.Nil(t, err)
+
+		assert.Equal(t, "custom-dlq.com", first)
+		assert.Equal(t, "custom-dlq.com", second)
+	})
+
+	t.Run("deadLetterQueueURL falls back to the error queue suffix when the queue has no redrive policy", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			GetQueueAttributes(gomock.Any(), gomock.Any()).
+			Return(&sqs.GetQueueAttributesOutput{}, nil)
+		sqsClient.
+			EXPECT().
+			GetQueueUrl(gomock.Any(), &sqs.GetQueueUrlInput{QueueName: aws.String("test-queue" + ErrorQueueSuffix)}).
+			Return(&sqs.GetQueueUrlOutput{QueueUrl: aws.String("test-queue_ERROR.com")}, nil)
+
+		dlqURL, err := sut.deadLetterQueueURL(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "test-queue_ERROR.com", dlqURL)
+	})
+}
+
+func TestSQSListener_deadLetter(t *testing.T) {
+	message := types.Message{
+		MessageId:     aws.String("test-id"),
+		Body:          aws.String("not json"),
+		ReceiptHandle: aws.String("test handle"),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"X-Correlation-ID": {DataType: aws.String("String"), StringValue: aws.String("correlation")},
+		},
+	}
+
+	t.Run("deadLetter forwards the message with the failure reason and deletes the original", func(t *testing.T) {
+		sut := getTestListener()
+		sut.dlqURL = "test-queue_ERROR.com"
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			SendMessage(gomock.Any(), &sqs.SendMessageInput{
+				QueueUrl:    aws.String("test-queue_ERROR.com"),
+				MessageBody: aws.String("not json"),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"X-Correlation-ID": {DataType: aws.String("String"), StringValue: aws.String("correlation")},
+					FailureAttribute:   {DataType: aws.String("String"), StringValue: aws.String(`{"reason":"permanent failure: malformed","originalMessageId":"test-id"}`)},
+				},
+			}).
+			Return(&sqs.SendMessageOutput{}, nil)
+		sqsClient.
+			EXPECT().
+			DeleteMessage(gomock.Any(), &sqs.DeleteMessageInput{
+				QueueUrl:      sut.queueURL,
+				ReceiptHandle: aws.String("test handle"),
+			}).
+			Return(&sqs.DeleteMessageOutput{}, nil)
+
+		moved := sut.deadLetter(message, Permanent(errors.New("malformed")))
+
+		assert.True(t, moved)
+	})
+
+	t.Run("deadLetter keeps the original message when forwarding fails", func(t *testing.T) {
+		sut := getTestListener()
+		sut.dlqURL = "test-queue_ERROR.com"
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			SendMessage(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("send error"))
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Times(0)
+
+		moved := sut.deadLetter(message, Permanent(errors.New("malformed")))
+
+		assert.False(t, moved)
+	})
+	t.Run("deadLetter keeps within the attribute limit for a message that already has 10 attributes", func(t *testing.T) {
+		sut := getTestListener()
+		sut.dlqURL = "test-queue_ERROR.com"
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		full := message
+		full.MessageAttributes = make(map[string]types.MessageAttributeValue, maxMessageAttributes)
+		for i := 0; i < maxMessageAttributes; i++ {
+			full.MessageAttributes[fmt.Sprintf("attribute-%d", i)] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String("value")}
+		}
+		sqsClient.
+			EXPECT().
+			SendMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+				assert.Equal(t, full.MessageAttributes, input.MessageAttributes)
+				return &sqs.SendMessageOutput{}, nil
+			})
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil)
+
+		moved := sut.deadLetter(full, Permanent(errors.New("malformed")))
+
+		assert.True(t, moved)
+	})
+}
+
+func TestDecodeFailure(t *testing.T) {
+	t.Run("DecodeFailure returns the failure of a dead letter", func(t *testing.T) {
+		message := types.Message{MessageAttributes: map[string]types.MessageAttributeValue{
+			FailureAttribute: {DataType: aws.String("String"), StringValue: aws.String(`{"reason":"malformed","originalMessageId":"test-id"}`)},
+		}}
+
+		failure, ok := DecodeFailure(message)
+
+		assert.True(t, ok)
+		assert.Equal(t, Failure{Reason: "malformed", OriginalMessageID: "test-id"}, failure)
+	})
+
+	t.Run("DecodeFailure reports messages without a failure", func(t *testing.T) {
+		_, ok := DecodeFailure(types.Message{})
+
+		assert.False(t, ok)
+	})
+}
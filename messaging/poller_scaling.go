@@ -0,0 +1,145 @@
+package zaws
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const DefaultPollerScaleInterval = 30 * time.Second
+
+// pollerSet runs poll loops that can be stopped one at a time. Stopped pollers finish their current receive
+// so that no received message is lost.
+type pollerSet struct {
+	poll  func(stop <-chan struct{})
+	wg    sync.WaitGroup
+	stops []chan struct{}
+}
+
+func (s *pollerSet) add() {
+	stop := make(chan struct{})
+	s.stops = append(s.stops, stop)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.poll(stop)
+	}()
+}
+
+func (s *pollerSet) remove() {
+	last := len(s.stops) - 1
+	close(s.stops[last])
+	s.stops = s.stops[:last]
+}
+
+func (s *pollerSet) size() int {
+	return len(s.stops)
+}
+
+// wait blocks until every poller has returned.
+func (s *pollerSet) wait() {
+	s.wg.Wait()
+}
+
+// runPollers runs MinPollers poll loops until ctx ends or the listener is drained. When MaxPollers is higher it checks the queue depth every
+// PollerScaleInterval to add or remove pollers.
+func (l *SQSListener) runPollers(ctx context.Context, request *sqs.ReceiveMessageInput) {
+	pollers := &pollerSet{poll: func(stop <-chan struct{}) {
+		l.poll(ctx, request, stop)
+	}}
+	for pollers.size() < l.minPollerCount() {
+		pollers.add()
+	}
+	l.stats.pollers.Store(int64(pollers.size()))
+
+	if l.maxPollerCount() > l.minPollerCount() {
+		l.autoscale(ctx, pollers)
+	}
+	pollers.wait()
+	l.stats.pollers.Store(0)
+}
+
+func (l *SQSListener) autoscale(ctx context.Context, pollers *pollerSet) {
+	interval := l.pollerScaleInterval
+	if interval <= 0 {
+		interval = DefaultPollerScaleInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, draining, changed := l.control.state()
+		if draining {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		case <-ticker.C:
+			depth, err := l.queueDepth(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					l.logger.Warnw("failed to read queue depth for poller scaling", "queue", l.queueName, "error", err)
+				}
+				continue
+			}
+			l.stats.queueDepth.Store(depth)
+			l.scalePollers(pollers, depth)
+		}
+	}
+}
+
+// scalePollers adds a poller while the backlog is larger than what the pollers receive in one round trip and
+// removes one when the queue is empty.
+func (l *SQSListener) scalePollers(pollers *pollerSet, depth int64) {
+	from := pollers.size()
+	switch {
+	case depth > int64(from*l.batchSize()) && from < l.maxPollerCount():
+		pollers.add()
+		l.stats.scaleUps.Add(1)
+	case depth == 0 && from > l.minPollerCount():
+		pollers.remove()
+		l.stats.scaleDowns.Add(1)
+	default:
+		return
+	}
+
+	l.stats.pollers.Store(int64(pollers.size()))
+	l.logger.Infow("scaled pollers",
+		"queue", l.queueName,
+		"from", from,
+		"to", pollers.size(),
+		"queueDepth", depth,
+	)
+}
+
+func (l *SQSListener) queueDepth(ctx context.Context) (int64, error) {
+	result, err := l.sqsClient.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+		QueueUrl:       l.queueURL,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(result.Attributes[string(types.QueueAttributeNameApproximateNumberOfMessages)], 10, 64)
+}
+
+func (l *SQSListener) minPollerCount() int {
+	if l.minPollers <= 0 {
+		return 1
+	}
+	return l.minPollers
+}
+
+func (l *SQSListener) maxPollerCount() int {
+	if l.maxPollers < l.minPollerCount() {
+		return l.minPollerCount()
+	}
+	return l.maxPollers
+}
@@ -0,0 +1,119 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestPollerSet(size int) *pollerSet {
+	pollers := &pollerSet{poll: func(stop <-chan struct{}) {
+		<-stop
+	}}
+	for pollers.size() < size {
+		pollers.add()
+	}
+	return pollers
+}
+
+func stopPollers(pollers *pollerSet) {
+	for pollers.size() > 0 {
+		pollers.remove()
+	}
+	pollers.wait()
+}
+
+func TestSQSListener_scalePollers(t *testing.T) {
+	tests := []struct {
+		name       string
+		minPollers int
+		maxPollers int
+		pollers    int
+		depth      int64
+		want       int
+	}{
+		{"scalePollers adds a poller when the backlog exceeds one receive per poller", 1, 4, 2, 21, 3},
+		{"scalePollers keeps the pollers when they can keep up with the backlog", 1, 4, 2, 20, 2},
+		{"scalePollers never goes above the maximum", 1, 4, 4, 1000, 4},
+		{"scalePollers removes a poller when the queue is empty", 1, 4, 3, 0, 2},
+		{"scalePollers never goes below the minimum", 2, 4, 2, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut := getTestListener()
+			sut.maxNumberOfMessages = 10
+			sut.minPollers = tt.minPollers
+			sut.maxPollers = tt.maxPollers
+			pollers := getTestPollerSet(tt.pollers)
+			defer stopPollers(pollers)
+
+			sut.scalePollers(pollers, tt.depth)
+
+			assert.Equal(t, tt.want, pollers.size())
+		})
+	}
+
+	t.Run("scalePollers counts its decisions in the stats", func(t *testing.T) {
+		sut := getTestListener()
+		sut.maxPollers = 2
+		pollers := getTestPollerSet(1)
+		defer stopPollers(pollers)
+
+		sut.scalePollers(pollers, 5)
+		sut.scalePollers(pollers, 0)
+		stats := sut.Stats()
+
+		assert.Equal(t, int64(1), stats.ScaleUps)
+		assert.Equal(t, int64(1), stats.ScaleDowns)
+		assert.Equal(t, int64(1), stats.Pollers)
+	})
+}
+
+func TestSQSListener_queueDepth(t *testing.T) {
+	t.Run("queueDepth returns the approximate number of messages of the queue", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			GetQueueAttributes(gomock.Any(), &sqs.GetQueueAttributesInput{
+				AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameApproximateNumberOfMessages},
+				QueueUrl:       sut.queueURL,
+			}).
+			Return(&sqs.GetQueueAttributesOutput{Attributes: map[string]string{"ApproximateNumberOfMessages": "42"}}, nil)
+
+		got, err := sut.queueDepth(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(42), got)
+	})
+
+	t.Run("queueDepth returns the error of GetQueueAttributes", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.EXPECT().GetQueueAttributes(gomock.Any(), gomock.Any()).Return(nil, errors.New("attributes error"))
+
+		_, err := sut.queueDepth(context.Background())
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSQSListener_poll_stop(t *testing.T) {
+	t.Run("poll returns without receiving once stop is closed", func(t *testing.T) {
+		sut := getTestListener()
+		sut.sqsClient = NewMockISQSClient(gomock.NewController(t))
+		sut.workers = newWorkerPool(1)
+		defer sut.workers.stop()
+		stop := make(chan struct{})
+		close(stop)
+
+		sut.poll(context.Background(), &sqs.ReceiveMessageInput{}, stop)
+	})
+}
@@ -0,0 +1,290 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snsTypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	// MaxBatchEntries and MaxBatchBytes are the limits of a single SendMessageBatch or PublishBatch call.
+	MaxBatchEntries    = 10
+	MaxBatchBytes      = 256 * 1024
+	errNoPublishResult = "no result for the batch entry"
+)
+
+var (
+	ErrBatchEntryTooLarge = errors.New("batch entry is larger than MaxBatchBytes")
+	ErrPublishBatchFailed = errors.New("some batch entries were not published")
+)
+
+// BatchEntry is one message of a PublishBatch call.
+type BatchEntry struct {
+	// ID identifies the entry in the results. It defaults to the index of the entry.
+	ID         string
+	Message    string
+	Attributes map[string]string
+	// TypedAttributes take precedence over Attributes with the same name, see WithAttributes.
+	TypedAttributes Attributes
+	// Subject is only used by topics. MultiTopicHandler expects the name of the topic.
+	Subject string
+	// MessageGroupID and DeduplicationID are only used by FIFO queues and topics, see WithMessageGroupID and
+	// WithDeduplicationID.
+	MessageGroupID  string
+	DeduplicationID string
+}
+
+// BatchEntryResult is the outcome of one BatchEntry. Err is nil when the entry was published.
+type BatchEntryResult struct {
+	ID string
+	PublishResult
+	Err error
+}
+
+// BatchEntryError is the failure SNS or SQS reported for one entry of a batch.
+type BatchEntryError struct {
+	Code        string
+	Message     string
+	SenderFault bool
+}
+
+func (e *BatchEntryError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// batchResponse maps the request entry IDs of a batch call to their outcome.
+type batchResponse struct {
+	succeeded map[string]PublishResult
+	failed    map[string]*BatchEntryError
+}
+
+// sendBatchFunc sends the entries at the given indexes in one call, using the indexes as request entry IDs.
+type sendBatchFunc func(ctx context.Context, indexes []int) (batchResponse, error)
+
+// PublishBatch sends entries to the queue with SendMessageBatch, in as many calls as the batch limits require.
+// Entries that failed for a reason other than the request itself are retried according to opts. It returns a
+// result for every entry, in order, and ErrPublishBatchFailed when at least one of them was not published.
+func (p *QueuePublisher) PublishBatch(ctx context.Context, entries []BatchEntry, opts ...Option) ([]BatchEntryResult, error) {
+	entries, results := offloadBatch(ctx, p.claimCheck, entries)
+	return publishBatch(ctx, entries, results, opts, func(ctx context.Context, indexes []int) (batchResponse, error) {
+		requestEntries := make([]sqsTypes.SendMessageBatchRequestEntry, 0, len(indexes))
+		for _, i := range indexes {
+			requestEntries = append(requestEntries, sqsTypes.SendMessageBatchRequestEntry{
+				Id:                     aws.String(strconv.Itoa(i)),
+				MessageBody:            aws.String(entries[i].Message),
+				MessageAttributes:      entries[i].attributes().sqs(),
+				MessageGroupId:         optionalString(entries[i].MessageGroupID),
+				MessageDeduplicationId: optionalString(entries[i].DeduplicationID),
+			})
+		}
+
+		output, err := p.sqsClient.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(p.queueURL),
+			Entries:  requestEntries,
+		})
+		if err != nil {
+			return batchResponse{}, err
+		}
+
+		response := batchResponse{succeeded: make(map[string]PublishResult), failed: make(map[string]*BatchEntryError)}
+		for _, entry := range output.Successful {
+			response.succeeded[aws.ToString(entry.Id)] = PublishResult{MessageID: aws.ToString(entry.MessageId), SequenceNumber: aws.ToString(entry.SequenceNumber)}
+		}
+		for _, entry := range output.Failed {
+			response.failed[aws.ToString(entry.Id)] = &BatchEntryError{Code: aws.ToString(entry.Code), Message: aws.ToString(entry.Message), SenderFault: entry.SenderFault}
+		}
+		return response, nil
+	})
+}
+
+// PublishBatch publishes entries to the topic with PublishBatch, see QueuePublisher.PublishBatch.
+func (p *TopicPublisher) PublishBatch(ctx context.Context, entries []BatchEntry, opts ...Option) ([]BatchEntryResult, error) {
+	return publishTopicBatch(ctx, p.snsClient, p.topicArn, p.claimCheck, entries, opts)
+}
+
+// PublishBatch publishes entries to topicName with PublishBatch, see QueuePublisher.PublishBatch.
+func (p *TopicsPublisher) PublishBatch(ctx context.Context, topicName string, entries []BatchEntry, opts ...Option) ([]BatchEntryResult, error) {
+	topicArn, err := p.getTopicArn(topicName)
+	if err != nil {
+		return nil, err
+	}
+	return publishTopicBatch(ctx, p.snsClient, topicArn, p.claimCheck, entries, opts)
+}
+
+func publishTopicBatch(ctx context.Context, snsClient ISNSClient, topicArn string, claimCheck ClaimCheckConfig, entries []BatchEntry, opts []Option) ([]BatchEntryResult, error) {
+	entries, results := offloadBatch(ctx, claimCheck, entries)
+	return publishBatch(ctx, entries, results, opts, func(ctx context.Context, indexes []int) (batchResponse, error) {
+		requestEntries := make([]snsTypes.PublishBatchRequestEntry, 0, len(indexes))
+		for _, i := range indexes {
+			requestEntries = append(requestEntries, snsTypes.PublishBatchRequestEntry{
+				Id:                     aws.String(strconv.Itoa(i)),
+				Message:                aws.String(entries[i].Message),
+				MessageAttributes:      entries[i].attributes().sns(),
+				Subject:                optionalString(entries[i].Subject),
+				MessageGroupId:         optionalString(entries[i].MessageGroupID),
+				MessageDeduplicationId: optionalString(entries[i].DeduplicationID),
+			})
+		}
+
+		output, err := snsClient.PublishBatch(ctx, &sns.PublishBatchInput{
+			TopicArn:                   aws.String(topicArn),
+			PublishBatchRequestEntries: requestEntries,
+		})
+		if err != nil {
+			return batchResponse{}, err
+		}
+
+		response := batchResponse{succeeded: make(map[string]PublishResult), failed: make(map[string]*BatchEntryError)}
+		for _, entry := range output.Successful {
+			response.succeeded[aws.ToString(entry.Id)] = PublishResult{MessageID: aws.ToString(entry.MessageId), SequenceNumber: aws.ToString(entry.SequenceNumber)}
+		}
+		for _, entry := range output.Failed {
+			response.failed[aws.ToString(entry.Id)] = &BatchEntryError{Code: aws.ToString(entry.Code), Message: aws.ToString(entry.Message), SenderFault: entry.SenderFault}
+		}
+		return response, nil
+	})
+}
+
+// offloadBatch applies the claim check to every entry. It returns the entries to send and their results, where the
+// entries that could not be offloaded already have an error.
+func offloadBatch(ctx context.Context, claimCheck ClaimCheckConfig, entries []BatchEntry) ([]BatchEntry, []BatchEntryResult) {
+	offloaded := make([]BatchEntry, len(entries))
+	results := make([]BatchEntryResult, len(entries))
+	for i, entry := range entries {
+		results[i].ID = entry.ID
+		if entry.ID == "" {
+			results[i].ID = strconv.Itoa(i)
+		}
+
+		message, claimCheckAttributes, err := claimCheck.offload(ctx, entry.Message)
+		if err != nil {
+			results[i].Err = err
+		}
+		entry.Message = message
+		if claimCheckAttributes != nil {
+			entry.TypedAttributes = entry.TypedAttributes.with(claimCheckAttributes)
+		}
+		offloaded[i] = entry
+	}
+	return offloaded, results
+}
+
+func publishBatch(ctx context.Context, entries []BatchEntry, results []BatchEntryResult, opts []Option, send sendBatchFunc) ([]BatchEntryResult, error) {
+	var config RetryConfig
+	config.Defaults()
+	for _, option := range opts {
+		option(&config)
+	}
+
+	var pending []int
+	for i, entry := range entries {
+		if results[i].Err != nil {
+			continue
+		}
+		if batchEntrySize(entry) > MaxBatchBytes {
+			results[i].Err = ErrBatchEntryTooLarge
+			continue
+		}
+		pending = append(pending, i)
+	}
+	for _, chunk := range chunkBatch(entries, pending) {
+		sendBatchChunk(ctx, chunk, results, config, send)
+	}
+
+	for _, result := range results {
+		if result.Err != nil {
+			return results, ErrPublishBatchFailed
+		}
+	}
+	return results, nil
+}
+
+// sendBatchChunk sends one chunk and retries its failed entries, or the whole chunk when the call itself fails.
+// Entries rejected because of their content are not retried.
+func sendBatchChunk(ctx context.Context, chunk []int, results []BatchEntryResult, config RetryConfig, send sendBatchFunc) {
+	for attempt := 0; len(chunk) > 0; attempt++ {
+		lastAttempt := attempt >= config.Attempts
+		response, err := send(ctx, chunk)
+		if err != nil {
+			for _, i := range chunk {
+				results[i].Err = err
+			}
+		} else {
+			var retry []int
+			for _, i := range chunk {
+				id := strconv.Itoa(i)
+				if result, ok := response.succeeded[id]; ok {
+					results[i].PublishResult = result
+					results[i].Err = nil
+					continue
+				}
+
+				failure, ok := response.failed[id]
+				if !ok {
+					results[i].Err = errors.New(errNoPublishResult)
+					continue
+				}
+				results[i].Err = failure
+				if !failure.SenderFault {
+					retry = append(retry, i)
+				}
+			}
+			chunk = retry
+		}
+
+		if lastAttempt || len(chunk) == 0 || ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(config.waitDuration(attempt)):
+		}
+	}
+}
+
+// chunkBatch splits the entries at indexes into chunks within MaxBatchEntries and MaxBatchBytes.
+func chunkBatch(entries []BatchEntry, indexes []int) [][]int {
+	var chunks [][]int
+	var chunk []int
+	chunkSize := 0
+	for _, i := range indexes {
+		size := batchEntrySize(entries[i])
+		if len(chunk) == MaxBatchEntries || chunkSize+size > MaxBatchBytes {
+			chunks = append(chunks, chunk)
+			chunk = nil
+			chunkSize = 0
+		}
+		chunk = append(chunk, i)
+		chunkSize += size
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// batchEntrySize is the size SNS and SQS count against MaxBatchBytes: the body and the name, type and value of
+// every attribute.
+func batchEntrySize(entry BatchEntry) int {
+	return len(entry.Message) + entry.attributes().size()
+}
+
+func (e BatchEntry) attributes() Attributes {
+	return StringAttributes(e.Attributes).with(e.TypedAttributes)
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
@@ -0,0 +1,193 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snsTypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkBatch(t *testing.T) {
+	t.Run("chunkBatch puts at most MaxBatchEntries entries in a chunk", func(t *testing.T) {
+		entries := make([]BatchEntry, 25)
+		indexes := make([]int, 25)
+		for i := range indexes {
+			indexes[i] = i
+		}
+
+		chunks := chunkBatch(entries, indexes)
+
+		assert.Len(t, chunks, 3)
+		assert.Len(t, chunks[0], 10)
+		assert.Len(t, chunks[1], 10)
+		assert.Equal(t, []int{20, 21, 22, 23, 24}, chunks[2])
+	})
+
+	t.Run("chunkBatch keeps every chunk within MaxBatchBytes", func(t *testing.T) {
+		message := strings.Repeat("a", 100*1024)
+		entries := []BatchEntry{{Message: message}, {Message: message}, {Message: message}}
+
+		chunks := chunkBatch(entries, []int{0, 1, 2})
+
+		assert.Equal(t, [][]int{{0, 1}, {2}}, chunks)
+	})
+}
+
+func TestQueuePublisher_PublishBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("PublishBatch retries only the entries that failed without a sender fault", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		publisher := &QueuePublisher{sqsClient: sqsClient, queueName: "test-queue", queueURL: "www.test-queue.com"}
+		gomock.InOrder(
+			sqsClient.
+				EXPECT().
+				SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+					QueueUrl: aws.String("www.test-queue.com"),
+					Entries: []sqsTypes.SendMessageBatchRequestEntry{
+						{Id: aws.String("0"), MessageBody: aws.String("message a")},
+						{
+							Id:          aws.String("1"),
+							MessageBody: aws.String("message b"),
+							MessageAttributes: map[string]sqsTypes.MessageAttributeValue{
+								"key": {DataType: aws.String("String"), StringValue: aws.String("value")},
+							},
+						},
+						{Id: aws.String("2"), MessageBody: aws.String("message c")},
+					},
+				}).
+				Return(&sqs.SendMessageBatchOutput{
+					Successful: []sqsTypes.SendMessageBatchResultEntry{{Id: aws.String("0"), MessageId: aws.String("id-a")}},
+					Failed: []sqsTypes.BatchResultErrorEntry{
+						{Id: aws.String("1"), Code: aws.String("InternalError"), Message: aws.String("try again")},
+						{Id: aws.String("2"), Code: aws.String("InvalidMessageContents"), Message: aws.String("invalid"), SenderFault: true},
+					},
+				}, nil),
+			sqsClient.
+				EXPECT().
+				SendMessageBatch(ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, input *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+					assert.Len(t, input.Entries, 1)
+					assert.Equal(t, "1", aws.ToString(input.Entries[0].Id))
+					return &sqs.SendMessageBatchOutput{
+						Successful: []sqsTypes.SendMessageBatchResultEntry{{Id: aws.String("1"), MessageId: aws.String("id-b")}},
+					}, nil
+				}),
+		)
+
+		results, err := publisher.PublishBatch(ctx, []BatchEntry{
+			{Message: "message a"},
+			{ID: "b", Message: "message b", Attributes: map[string]string{"key": "value"}},
+			{Message: "message c"},
+		}, WithConstant(2, time.Millisecond))
+
+		assert.ErrorIs(t, err, ErrPublishBatchFailed)
+		assert.Equal(t, []BatchEntryResult{
+			{ID: "0", PublishResult: PublishResult{MessageID: "id-a"}},
+			{ID: "b", PublishResult: PublishResult{MessageID: "id-b"}},
+			{ID: "2", Err: &BatchEntryError{Code: "InvalidMessageContents", Message: "invalid", SenderFault: true}},
+		}, results)
+	})
+
+	t.Run("PublishBatch rejects an entry larger than MaxBatchBytes without sending it", func(t *testing.T) {
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		publisher := &QueuePublisher{sqsClient: sqsClient, queueName: "test-queue", queueURL: "www.test-queue.com"}
+		sqsClient.EXPECT().SendMessageBatch(gomock.Any(), gomock.Any()).Times(0)
+
+		results, err := publisher.PublishBatch(ctx, []BatchEntry{{Message: strings.Repeat("a", MaxBatchBytes+1)}})
+
+		assert.ErrorIs(t, err, ErrPublishBatchFailed)
+		assert.ErrorIs(t, results[0].Err, ErrBatchEntryTooLarge)
+	})
+}
+
+func TestTopicPublisher_PublishBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("PublishBatch publishes the entries with their subject and group", func(t *testing.T) {
+		snsClient, publisher, topicName, topicArn := publisherTestSetup(t)
+		snsClient.
+			EXPECT().
+			PublishBatch(ctx, &sns.PublishBatchInput{
+				TopicArn: aws.String(topicArn),
+				PublishBatchRequestEntries: []snsTypes.PublishBatchRequestEntry{
+					{Id: aws.String("0"), Message: aws.String("message a"), Subject: aws.String(topicName), MessageGroupId: aws.String("group")},
+				},
+			}).
+			Return(&sns.PublishBatchOutput{
+				Successful: []snsTypes.PublishBatchResultEntry{{Id: aws.String("0"), MessageId: aws.String("id-a"), SequenceNumber: aws.String("1")}},
+			}, nil)
+
+		results, err := publisher.PublishBatch(ctx, []BatchEntry{{Message: "message a", Subject: topicName, MessageGroupID: "group"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []BatchEntryResult{{ID: "0", PublishResult: PublishResult{MessageID: "id-a", SequenceNumber: "1"}}}, results)
+	})
+
+	t.Run("PublishBatch sends the typed attributes of the entries", func(t *testing.T) {
+		snsClient, publisher, _, topicArn := publisherTestSetup(t)
+		snsClient.
+			EXPECT().
+			PublishBatch(ctx, &sns.PublishBatchInput{
+				TopicArn: aws.String(topicArn),
+				PublishBatchRequestEntries: []snsTypes.PublishBatchRequestEntry{
+					{
+						Id:      aws.String("0"),
+						Message: aws.String("message a"),
+						MessageAttributes: map[string]snsTypes.MessageAttributeValue{
+							"key":    {DataType: aws.String("String"), StringValue: aws.String("value")},
+							"amount": {DataType: aws.String("Number"), StringValue: aws.String("3")},
+						},
+					},
+				},
+			}).
+			Return(&sns.PublishBatchOutput{Successful: []snsTypes.PublishBatchResultEntry{{Id: aws.String("0")}}}, nil)
+
+		_, err := publisher.PublishBatch(ctx, []BatchEntry{{
+			Message:         "message a",
+			Attributes:      map[string]string{"key": "value"},
+			TypedAttributes: NewAttributes().Int("amount", 3),
+		}})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("PublishBatch reports the error of the call for every entry once the retries are exhausted", func(t *testing.T) {
+		snsClient, publisher, _, _ := publisherTestSetup(t)
+		publishErr := errors.New("publish error")
+		snsClient.EXPECT().PublishBatch(ctx, gomock.Any()).Return(nil, publishErr).Times(2)
+
+		results, err := publisher.PublishBatch(ctx, []BatchEntry{{Message: "message a"}, {Message: "message b"}}, WithConstant(1, time.Millisecond))
+
+		assert.ErrorIs(t, err, ErrPublishBatchFailed)
+		assert.Equal(t, publishErr, results[0].Err)
+		assert.Equal(t, publishErr, results[1].Err)
+	})
+}
+
+func TestTopicsPublisher_PublishBatch(t *testing.T) {
+	t.Run("PublishBatch publishes the entries to the topic with the given name", func(t *testing.T) {
+		snsClient, publisher, testTopic := setup(t)
+		snsClient.
+			EXPECT().
+			PublishBatch(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *sns.PublishBatchInput, _ ...func(*sns.Options)) (*sns.PublishBatchOutput, error) {
+				assert.Equal(t, "test-arn", aws.ToString(input.TopicArn))
+				return &sns.PublishBatchOutput{Successful: []snsTypes.PublishBatchResultEntry{{Id: aws.String("0"), MessageId: aws.String("id-a")}}}, nil
+			})
+
+		results, err := publisher.PublishBatch(context.Background(), testTopic, []BatchEntry{{Message: "message a"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "id-a", results[0].MessageID)
+	})
+}
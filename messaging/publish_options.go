@@ -0,0 +1,50 @@
+package zaws
+
+import "github.com/aws/aws-sdk-go-v2/aws"
+
+// PublishResult identifies a published message. SequenceNumber is only set for FIFO queues and topics.
+type PublishResult struct {
+	MessageID      string
+	SequenceNumber string
+}
+
+// PublishOption customises a single publish call.
+type PublishOption func(*publishOptions)
+
+type publishOptions struct {
+	messageGroupID  *string
+	deduplicationID *string
+	attributes      Attributes
+}
+
+// WithMessageGroupID sets the message group of a message sent to a FIFO queue or topic. Messages of the same
+// group are delivered in order.
+func WithMessageGroupID(groupID string) PublishOption {
+	return func(o *publishOptions) {
+		o.messageGroupID = aws.String(groupID)
+	}
+}
+
+// WithDeduplicationID sets the deduplication ID of a message sent to a FIFO queue or topic. It is required unless
+// content-based deduplication is enabled.
+func WithDeduplicationID(deduplicationID string) PublishOption {
+	return func(o *publishOptions) {
+		o.deduplicationID = aws.String(deduplicationID)
+	}
+}
+
+// WithAttributes adds typed message attributes, see Attributes. They take precedence over string attributes with the
+// same name.
+func WithAttributes(attributes Attributes) PublishOption {
+	return func(o *publishOptions) {
+		o.attributes = o.attributes.with(attributes)
+	}
+}
+
+func newPublishOptions(opts []PublishOption) publishOptions {
+	var options publishOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
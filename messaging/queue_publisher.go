@@ -3,22 +3,23 @@ package zaws
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
-
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 )
 
 type IQueuePublisher interface {
-	Publish(message string) error
-	PublishWithAttributes(message string, attributes map[string]string) error
+	Publish(message string, opts ...PublishOption) error
+	PublishWithAttributes(message string, attributes map[string]string, opts ...PublishOption) error
+	PublishCtx(ctx context.Context, message string, opts ...PublishOption) (PublishResult, error)
+	PublishWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error)
 }
 
 type QueuePublisher struct {
-	sqsClient ISQSClient
-	queueName string
-	queueURL  string
+	sqsClient  ISQSClient
+	queueName  string
+	queueURL   string
+	claimCheck ClaimCheckConfig
 }
 
 func NewQueuePublisher(region string, queueName string) (*QueuePublisher, error) {
@@ -49,25 +50,45 @@ func NewQueuePublisherWithConfig(cfg aws.Config, queueName string) (*QueuePublis
 	}, nil
 }
 
-func (p *QueuePublisher) Publish(message string) error {
-	ctx := context.Background()
-	_, err := p.sqsClient.SendMessage(ctx, &sqs.SendMessageInput{
-		MessageBody: aws.String(message),
-		QueueUrl:    aws.String(p.queueURL),
-	})
+// SetClaimCheck makes the publisher store message bodies above the threshold in a blob store, see ClaimCheckConfig.
+func (p *QueuePublisher) SetClaimCheck(config ClaimCheckConfig) {
+	p.claimCheck = config
+}
+
+func (p *QueuePublisher) Publish(message string, opts ...PublishOption) error {
+	_, err := p.PublishCtx(context.Background(), message, opts...)
 	return err
 }
 
-func (p *QueuePublisher) PublishWithAttributes(message string, attributes map[string]string) error {
-	ctx := context.Background()
-	messageAttributesMap := make(map[string]types.MessageAttributeValue)
-	for key, val := range attributes {
-		messageAttributesMap[key] = types.MessageAttributeValue{StringValue: &val}
+func (p *QueuePublisher) PublishWithAttributes(message string, attributes map[string]string, opts ...PublishOption) error {
+	_, err := p.PublishWithAttributesCtx(context.Background(), message, attributes, opts...)
+	return err
+}
+
+// PublishCtx sends message to the queue and returns the ID SQS assigned to it.
+func (p *QueuePublisher) PublishCtx(ctx context.Context, message string, opts ...PublishOption) (PublishResult, error) {
+	return p.send(ctx, message, nil, opts)
+}
+
+func (p *QueuePublisher) PublishWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error) {
+	return p.send(ctx, message, attributes, opts)
+}
+
+func (p *QueuePublisher) send(ctx context.Context, message string, attributes map[string]string, opts []PublishOption) (PublishResult, error) {
+	options := newPublishOptions(opts)
+	message, claimCheckAttributes, err := p.claimCheck.offload(ctx, message)
+	if err != nil {
+		return PublishResult{}, err
 	}
-	_, err := p.sqsClient.SendMessage(ctx, &sqs.SendMessageInput{
-		MessageBody:       aws.String(message),
-		QueueUrl:          aws.String(p.queueURL),
-		MessageAttributes: messageAttributesMap,
+	result, err := p.sqsClient.SendMessage(ctx, &sqs.SendMessageInput{
+		MessageBody:            aws.String(message),
+		QueueUrl:               aws.String(p.queueURL),
+		MessageAttributes:      StringAttributes(attributes).with(options.attributes).with(claimCheckAttributes).sqs(),
+		MessageGroupId:         options.messageGroupID,
+		MessageDeduplicationId: options.deduplicationID,
 	})
-	return err
+	if err != nil {
+		return PublishResult{}, err
+	}
+	return PublishResult{MessageID: aws.ToString(result.MessageId), SequenceNumber: aws.ToString(result.SequenceNumber)}, nil
 }
@@ -5,8 +5,6 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/ammyy9908/go-common-libraries/messaging/mock"
-
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -28,7 +26,7 @@ func TestNewQueuePublisherWithConfig(t *testing.T) {
 }
 
 func TestQueuePublisher_Publish(t *testing.T) {
-	sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
 	ctx := context.Background()
 
 	publisher := &QueuePublisher{
@@ -64,10 +62,26 @@ func TestQueuePublisher_Publish(t *testing.T) {
 
 		assert.NotNil(t, err)
 	})
+
+	t.Run("Publish sets the message group and deduplication IDs from the publish options", func(t *testing.T) {
+		sqsClient.
+			EXPECT().
+			SendMessage(ctx, &sqs.SendMessageInput{
+				MessageBody:            aws.String("test message"),
+				QueueUrl:               aws.String(publisher.queueURL),
+				MessageGroupId:         aws.String("account-1"),
+				MessageDeduplicationId: aws.String("entry-1"),
+			}).
+			Return(&sqs.SendMessageOutput{}, nil)
+
+		err := publisher.Publish("test message", WithMessageGroupID("account-1"), WithDeduplicationID("entry-1"))
+
+		assert.Nil(t, err)
+	})
 }
 
 func TestQueuePublisher_PublishWithAttributes(t *testing.T) {
-	sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
 	ctx := context.Background()
 
 	publisher := &QueuePublisher{
@@ -84,7 +98,7 @@ func TestQueuePublisher_PublishWithAttributes(t *testing.T) {
 				MessageBody: aws.String("test message"),
 				QueueUrl:    aws.String(publisher.queueURL),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &dummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &dummyUUID},
 				},
 			}).
 			Return(&sqs.SendMessageOutput{}, nil)
@@ -104,7 +118,7 @@ func TestQueuePublisher_PublishWithAttributes(t *testing.T) {
 				MessageBody: aws.String("test message"),
 				QueueUrl:    aws.String(publisher.queueURL),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &dummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &dummyUUID},
 				},
 			}).
 			Return(&sqs.SendMessageOutput{}, errors.New("test error"))
@@ -115,4 +129,80 @@ func TestQueuePublisher_PublishWithAttributes(t *testing.T) {
 
 		assert.NotNil(t, err)
 	})
+
+	t.Run("PublishWithAttributes sends every attribute with its own value", func(t *testing.T) {
+		sqsClient.
+			EXPECT().
+			SendMessage(ctx, &sqs.SendMessageInput{
+				MessageBody: aws.String("test message"),
+				QueueUrl:    aws.String(publisher.queueURL),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"first":  {DataType: aws.String("String"), StringValue: aws.String("1")},
+					"second": {DataType: aws.String("String"), StringValue: aws.String("2")},
+				},
+			}).
+			Return(&sqs.SendMessageOutput{}, nil)
+
+		err := publisher.PublishWithAttributes("test message", map[string]string{"first": "1", "second": "2"})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("PublishWithAttributes adds the typed attributes of WithAttributes", func(t *testing.T) {
+		sqsClient.
+			EXPECT().
+			SendMessage(ctx, &sqs.SendMessageInput{
+				MessageBody: aws.String("test message"),
+				QueueUrl:    aws.String(publisher.queueURL),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"first":  {DataType: aws.String("String"), StringValue: aws.String("1")},
+					"amount": {DataType: aws.String("Number"), StringValue: aws.String("12")},
+				},
+			}).
+			Return(&sqs.SendMessageOutput{}, nil)
+
+		err := publisher.PublishWithAttributes("test message", map[string]string{"first": "1"}, WithAttributes(NewAttributes().Int("amount", 12)))
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestQueuePublisher_PublishCtx(t *testing.T) {
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
+	type contextKey struct{}
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+	publisher := &QueuePublisher{
+		sqsClient: sqsClient,
+		queueName: "test-queue.fifo",
+		queueURL:  "www.test-queue.fifo",
+	}
+
+	t.Run("PublishCtx sends the message with the given context and returns its ID and sequence number", func(t *testing.T) {
+		sqsClient.
+			EXPECT().
+			SendMessage(ctx, &sqs.SendMessageInput{
+				MessageBody:    aws.String("test message"),
+				QueueUrl:       aws.String(publisher.queueURL),
+				MessageGroupId: aws.String("group"),
+			}).
+			Return(&sqs.SendMessageOutput{MessageId: aws.String("test-id"), SequenceNumber: aws.String("1")}, nil)
+
+		result, err := publisher.PublishCtx(ctx, "test message", WithMessageGroupID("group"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, PublishResult{MessageID: "test-id", SequenceNumber: "1"}, result)
+	})
+
+	t.Run("PublishWithAttributesCtx returns the error of the message sending", func(t *testing.T) {
+		sqsClient.
+			EXPECT().
+			SendMessage(ctx, gomock.Any()).
+			Return(nil, errors.New("test error"))
+
+		result, err := publisher.PublishWithAttributesCtx(ctx, "test message", map[string]string{"key": "value"})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, PublishResult{}, result)
+	})
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -14,6 +15,7 @@ import (
 
 const (
 	ErrorQueueSuffix                   = "_ERROR"
+	FifoSuffix                         = ".fifo"
 	errQueueNameTooLong                = "queue name is too long"
 	errNonAlphaNumericCharsInQueueName = "queue name contains non alphanumeric characters"
 	errQueueNameEmpty                  = "queue name cannot be empty"
@@ -26,6 +28,7 @@ type QueueConfig struct {
 	DelaySeconds              int
 	MaxMessageRetentionPeriod int
 	DefaultVisibilityTimeout  int
+	ContentBasedDeduplication bool // Only used by FIFO queues, whose names end with FifoSuffix
 }
 
 func GetQueueURL(sqsClient ISQSClient, queueName string) (string, error) {
@@ -44,7 +47,7 @@ func setupNewQueue(sqsClient ISQSClient, queueName string, config QueueConfig) (
 	if err != nil {
 		return nil, err
 	}
-	dlqName := queueName + ErrorQueueSuffix
+	dlqName := deadLetterQueueName(queueName)
 	dlqResult, err := createQueue(sqsClient, dlqName, config)
 	if err != nil {
 		return nil, err
@@ -66,15 +69,20 @@ func createQueue(sqsClient ISQSClient, queueName string, config QueueConfig) (*s
 	delaySeconds := strconv.Itoa(config.DelaySeconds)
 	maxMessageRetentionPeriod := strconv.Itoa(config.MaxMessageRetentionPeriod)
 	defaultVisibilityTimeout := strconv.Itoa(config.DefaultVisibilityTimeout)
+	attributes := map[string]string{
+		"ReceiveMessageWaitTimeSeconds": waitTime,
+		"DelaySeconds":                  delaySeconds,
+		"MessageRetentionPeriod":        maxMessageRetentionPeriod,
+		"VisibilityTimeout":             defaultVisibilityTimeout,
+	}
+	if IsFifo(queueName) {
+		attributes[string(types.QueueAttributeNameFifoQueue)] = "true"
+		attributes[string(types.QueueAttributeNameContentBasedDeduplication)] = strconv.FormatBool(config.ContentBasedDeduplication)
+	}
 	result, err := sqsClient.CreateQueue(ctx, &sqs.CreateQueueInput{
-		Attributes: map[string]string{
-			"ReceiveMessageWaitTimeSeconds": waitTime,
-			"DelaySeconds":                  delaySeconds,
-			"MessageRetentionPeriod":        maxMessageRetentionPeriod,
-			"VisibilityTimeout":             defaultVisibilityTimeout,
-		},
-		QueueName: aws.String(queueName),
-		Tags:      queueTags,
+		Attributes: attributes,
+		QueueName:  aws.String(queueName),
+		Tags:       queueTags,
 	})
 	if err != nil {
 		return nil, err
@@ -135,7 +143,7 @@ func validateQueueName(queueName string) error {
 		return errors.New(errQueueNameEmpty)
 	}
 
-	ok, err := regexp.MatchString("^[a-zA-Z0-9-_]+$", queueName)
+	ok, err := regexp.MatchString("^[a-zA-Z0-9-_]+$", strings.TrimSuffix(queueName, FifoSuffix))
 	if !ok {
 		return errors.New(errNonAlphaNumericCharsInQueueName)
 	}
@@ -144,3 +152,16 @@ func validateQueueName(queueName string) error {
 	}
 	return nil
 }
+
+// IsFifo reports whether a queue or topic name is the name of a FIFO queue or topic.
+func IsFifo(name string) bool {
+	return strings.HasSuffix(name, FifoSuffix)
+}
+
+// deadLetterQueueName keeps the FifoSuffix at the end of the name, as FIFO queues need a FIFO dead-letter queue.
+func deadLetterQueueName(queueName string) string {
+	if IsFifo(queueName) {
+		return strings.TrimSuffix(queueName, FifoSuffix) + ErrorQueueSuffix + FifoSuffix
+	}
+	return queueName + ErrorQueueSuffix
+}
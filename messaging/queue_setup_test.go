@@ -8,8 +8,6 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/ammyy9908/go-common-libraries/messaging/mock"
-
 	"github.com/goccy/go-json"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -50,6 +48,8 @@ func Test_validateQueueName(t *testing.T) {
 			{"TEST-QUEUE"},
 			{"TEST_QUEUE_ERROR"},
 			{"test-queue_ERROR"},
+			{"test-queue.fifo"},
+			{"test-queue_ERROR.fifo"},
 			{strings.Repeat("q", 80)},
 		}
 		for _, tt := range tests {
@@ -70,6 +70,8 @@ func Test_validateQueueName(t *testing.T) {
 			{"test-queue 1"},
 			{"@Test-Queue"},
 			{"test_QUEUE#3"},
+			{"test.queue"},
+			{"test-queue.fifo.fifo"},
 			{""},
 			{strings.Repeat("q", 81)},
 		}
@@ -86,7 +88,7 @@ func Test_validateQueueName(t *testing.T) {
 func Test_getQueueArn(t *testing.T) {
 	ctx := context.Background()
 	t.Run("getQueueArn returns an ARN when given a queue URL", func(t *testing.T) {
-		sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
 		queueURL := "https://sqs.ap-south-1.amazonaws.com/000000000000000/test-queue"
 
 		sqsClient.
@@ -109,7 +111,7 @@ func TestGetQueueUrl(t *testing.T) {
 	ctx := context.Background()
 	t.Run("getQueueURL returns queue url when given the queue name", func(t *testing.T) {
 		queueName := "test-queue"
-		sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
 		sqsClient.
 			EXPECT().
 			GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
@@ -126,7 +128,7 @@ func TestGetQueueUrl(t *testing.T) {
 
 	t.Run("getQueueURL returns error when the queue doesn't exist", func(t *testing.T) {
 		queueName := "test-queue"
-		sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
 		sqsClient.
 			EXPECT().
 			GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
@@ -151,7 +153,7 @@ func Test_createQueue(t *testing.T) {
 		"ownerEmail":   "owner-email",
 	}
 	queueName := "test-queue"
-	sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
 
 	t.Run("createQueue creates a queue when given a a name and tags", func(t *testing.T) {
 		sqsClient.
@@ -204,11 +206,37 @@ func Test_createQueue(t *testing.T) {
 		assert.NotNil(t, err)
 
 	})
+
+	t.Run("createQueue creates a FIFO queue when the queue name ends with the fifo suffix", func(t *testing.T) {
+		attributes := returnAwsQueueAttributesQueue()
+		attributes["FifoQueue"] = "true"
+		attributes["ContentBasedDeduplication"] = "true"
+		sqsClient.
+			EXPECT().
+			CreateQueue(ctx,
+				&sqs.CreateQueueInput{
+					Attributes: attributes,
+					QueueName:  aws.String("test-queue.fifo"),
+					Tags:       createQueueTags(tags),
+				}).
+			Return(&sqs.CreateQueueOutput{QueueUrl: aws.String("https://sqs.ap-south-1.amazonaws.com/000000000000000/test-queue.fifo")}, nil)
+		c := returnExpectedQueueConfigQueue(tags)
+		c.ContentBasedDeduplication = true
+
+		_, err := createQueue(sqsClient, "test-queue.fifo", c)
+
+		assert.Nil(t, err)
+	})
+}
+
+func Test_deadLetterQueueName(t *testing.T) {
+	assert.Equal(t, "test-queue_ERROR", deadLetterQueueName("test-queue"))
+	assert.Equal(t, "test-queue_ERROR.fifo", deadLetterQueueName("test-queue.fifo"))
 }
 
 func Test_setUpDlq(t *testing.T) {
 	ctx := context.Background()
-	sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
 
 	t.Run("setUpDeadLetterQueue sets up queue attributes for dead-letter-queue when queue is created", func(t *testing.T) {
 		queueURL := "https://sqs.ap-south-1.amazonaws.com/000000000000000/test-queue"
@@ -262,7 +290,7 @@ func Test_setupNewQueue(t *testing.T) {
 	}
 	dlqURL := "https://sqs.ap-south-1.amazonaws.com/000000000000000/test-queue_ERROR"
 	dlqARN := "arn:aws:sqs:ap-south-1:000000000000000:test-queue_ERROR"
-	sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
 
 	sqsClient.
 		EXPECT().
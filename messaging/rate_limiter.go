@@ -0,0 +1,106 @@
+package zaws
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket limits how many messages are received per second. The zero value does not limit anything.
+type tokenBucket struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	changed chan struct{}
+}
+
+// setLimit changes the rate in tokens per second and the burst. A rate of 0 or less removes the limit. The burst
+// defaults to the rate rounded up and is at least 1.
+func (b *tokenBucket) setLimit(rate float64, burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+
+	wasLimited := b.rate > 0
+	b.rate = rate
+	b.burst = float64(burst)
+	if burst <= 0 {
+		b.burst = math.Ceil(rate)
+	}
+	if b.burst < 1 {
+		b.burst = 1
+	}
+	if !wasLimited || b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+
+	if b.changed != nil {
+		close(b.changed)
+		b.changed = nil
+	}
+}
+
+// take waits until at least one token is available and takes up to max tokens.
+func (b *tokenBucket) take(ctx context.Context, max int) (int, error) {
+	for {
+		b.mu.Lock()
+		if b.rate <= 0 {
+			b.mu.Unlock()
+			return max, nil
+		}
+
+		b.refill(time.Now())
+		if b.tokens >= 1 {
+			taken := math.Min(math.Floor(b.tokens), float64(max))
+			b.tokens -= taken
+			b.mu.Unlock()
+			return int(taken), nil
+		}
+
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		if b.changed == nil {
+			b.changed = make(chan struct{})
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		case <-changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// refund gives back tokens that were taken but not used.
+func (b *tokenBucket) refund(n int) {
+	if n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate <= 0 {
+		return
+	}
+	b.tokens = math.Min(b.tokens+float64(n), b.burst)
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if b.rate > 0 && !b.last.IsZero() {
+		b.tokens = math.Min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+	}
+	b.last = now
+}
+
+// SetRateLimit changes how many messages per second the listener receives at most while it runs, allowing bursts
+// of up to burst messages. A rate of 0 or less removes the limit.
+func (l *SQSListener) SetRateLimit(rate float64, burst int) {
+	l.limiter.setLimit(rate, burst)
+}
@@ -0,0 +1,122 @@
+package zaws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tokenBucket(t *testing.T) {
+	t.Run("take does not limit a bucket without a rate", func(t *testing.T) {
+		var sut tokenBucket
+
+		got, err := sut.take(context.Background(), 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 10, got)
+	})
+
+	t.Run("take returns at most the tokens of the burst", func(t *testing.T) {
+		var sut tokenBucket
+		sut.setLimit(1, 3)
+
+		got, err := sut.take(context.Background(), 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, got)
+	})
+
+	t.Run("take waits for the next token once the bucket is empty", func(t *testing.T) {
+		var sut tokenBucket
+		sut.setLimit(20, 1)
+		_, _ = sut.take(context.Background(), 1)
+		startedAt := time.Now()
+
+		got, err := sut.take(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, got)
+		assert.GreaterOrEqual(t, time.Since(startedAt), 40*time.Millisecond)
+	})
+
+	t.Run("take returns the context error when the context ends while waiting", func(t *testing.T) {
+		var sut tokenBucket
+		sut.setLimit(0.001, 1)
+		_, _ = sut.take(context.Background(), 1)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := sut.take(ctx, 1)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("take wakes up when the limit is removed", func(t *testing.T) {
+		var sut tokenBucket
+		sut.setLimit(0.001, 1)
+		_, _ = sut.take(context.Background(), 1)
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			sut.setLimit(0, 0)
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		got, err := sut.take(ctx, 5)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, got)
+	})
+
+	t.Run("refund gives back unused tokens up to the burst", func(t *testing.T) {
+		var sut tokenBucket
+		sut.setLimit(0.001, 4)
+		_, _ = sut.take(context.Background(), 4)
+
+		sut.refund(10)
+		got, _ := sut.take(context.Background(), 10)
+
+		assert.Equal(t, 4, got)
+	})
+}
+
+func TestSQSListener_Run_rateLimit(t *testing.T) {
+	t.Run("Run receives no more messages than there are tokens and refunds the ones not received", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.gracefulShutdownManager = nil
+		sut.maxNumberOfMessages = 10
+		sut.SetRateLimit(0.001, 3)
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		sut.handler = NewMockMessageHandler(ctrl)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		gomock.InOrder(
+			sqsClient.
+				EXPECT().
+				ReceiveMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, input *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+					assert.Equal(t, int32(3), input.MaxNumberOfMessages)
+					return &sqs.ReceiveMessageOutput{}, nil
+				}),
+			sqsClient.
+				EXPECT().
+				ReceiveMessage(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, input *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+					assert.Equal(t, int32(3), input.MaxNumberOfMessages)
+					cancel()
+					return nil, context.Canceled
+				}),
+		)
+
+		err := sut.Run(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,79 @@
+package zaws
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	DefaultReceiveBackoffBase = 100 * time.Millisecond
+	DefaultReceiveBackoffMax  = 30 * time.Second
+)
+
+// HealthChangeFunc is called when the receive circuit of a listener opens (healthy is false and err is the
+// last receive error) or closes again after a successful receive (healthy is true and err is nil).
+type HealthChangeFunc func(healthy bool, err error)
+
+// Healthy reports whether the listener is able to receive messages, i.e. its receive circuit is closed.
+func (l *SQSListener) Healthy() bool {
+	return !l.circuitOpen.Load()
+}
+
+// receiveFailed records a failed receive, opens the circuit once the configured threshold of consecutive failures
+// is reached and waits with exponential backoff and jitter before the next receive. It returns false when ctx
+// ended while waiting.
+func (l *SQSListener) receiveFailed(ctx context.Context, err error) bool {
+	failures := l.receiveFailures.Add(1)
+	l.logger.Errorw("failed to receive messages", "consecutiveFailures", failures, "error", err)
+
+	if l.circuitBreakerThreshold > 0 && failures >= int64(l.circuitBreakerThreshold) &&
+		l.circuitOpen.CompareAndSwap(false, true) {
+		l.logger.Errorw("receive circuit opened, listener is unhealthy", "queue", l.queueName, "consecutiveFailures", failures)
+		if l.onHealthChange != nil {
+			l.onHealthChange(false, err)
+		}
+	}
+
+	timer := time.NewTimer(l.receiveBackoff(failures))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// receiveSucceeded resets the backoff and closes the circuit if it was open.
+func (l *SQSListener) receiveSucceeded() {
+	l.stats.lastSuccessfulPoll.Store(time.Now().UnixNano())
+	l.receiveFailures.Store(0)
+	if l.circuitOpen.CompareAndSwap(true, false) {
+		l.logger.Infow("receive circuit closed, listener is healthy again", "queue", l.queueName)
+		if l.onHealthChange != nil {
+			l.onHealthChange(true, nil)
+		}
+	}
+}
+
+// receiveBackoff returns a random wait between half and all of base * 2^(failures-1), capped at the maximum.
+func (l *SQSListener) receiveBackoff(failures int64) time.Duration {
+	base := l.receiveBackoffBase
+	if base <= 0 {
+		base = DefaultReceiveBackoffBase
+	}
+	max := l.receiveBackoffMax
+	if max <= 0 {
+		max = DefaultReceiveBackoffMax
+	}
+
+	wait := time.Duration(float64(base) * math.Exp2(float64(failures-1)))
+	if wait > max || wait <= 0 {
+		wait = max
+	}
+	half := int64(wait / 2)
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return time.Duration(half + random.Int63n(half+1))
+}
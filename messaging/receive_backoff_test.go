@@ -0,0 +1,91 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQSListener_receiveBackoff(t *testing.T) {
+	sut := getTestListener()
+	sut.receiveBackoffBase = 100 * time.Millisecond
+	sut.receiveBackoffMax = time.Second
+
+	t.Run("receiveBackoff waits between half and all of the base after the first failure", func(t *testing.T) {
+		wait := sut.receiveBackoff(1)
+
+		assert.GreaterOrEqual(t, wait, 50*time.Millisecond)
+		assert.LessOrEqual(t, wait, 100*time.Millisecond)
+	})
+
+	t.Run("receiveBackoff grows exponentially with consecutive failures", func(t *testing.T) {
+		wait := sut.receiveBackoff(3)
+
+		assert.GreaterOrEqual(t, wait, 200*time.Millisecond)
+		assert.LessOrEqual(t, wait, 400*time.Millisecond)
+	})
+
+	t.Run("receiveBackoff never waits longer than the maximum", func(t *testing.T) {
+		wait := sut.receiveBackoff(100)
+
+		assert.LessOrEqual(t, wait, time.Second)
+	})
+}
+
+func TestSQSListener_receiveFailed(t *testing.T) {
+	t.Run("receiveFailed returns false when the context ends while waiting", func(t *testing.T) {
+		sut := getTestListener()
+		sut.receiveBackoffBase = time.Hour
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		ok := sut.receiveFailed(ctx, errors.New("receive error"))
+
+		assert.False(t, ok)
+	})
+}
+
+func TestSQSListener_Run_circuitBreaker(t *testing.T) {
+	t.Run("Run opens the circuit after consecutive receive failures and closes it after a successful receive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.receiveBackoffBase = time.Millisecond
+		sut.circuitBreakerThreshold = 2
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		sut.handler = NewMockMessageHandler(ctrl)
+		receiveErr := errors.New("receive error")
+		var healthChanges []bool
+		var healthyDuringOutage bool
+		sut.onHealthChange = func(healthy bool, err error) {
+			healthChanges = append(healthChanges, healthy)
+			if !healthy {
+				assert.Equal(t, receiveErr, err)
+				healthyDuringOutage = sut.Healthy()
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		gomock.InOrder(
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).Return(nil, receiveErr).Times(3),
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).Return(&sqs.ReceiveMessageOutput{}, nil),
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, *sqs.ReceiveMessageInput, ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				cancel()
+				return nil, context.Canceled
+			}),
+		)
+
+		err := sut.Run(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []bool{false, true}, healthChanges)
+		assert.False(t, healthyDuringOutage)
+		assert.True(t, sut.Healthy())
+	})
+}
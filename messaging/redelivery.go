@@ -0,0 +1,103 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// MaxVisibilityTimeout is the longest visibility timeout SQS accepts.
+const MaxVisibilityTimeout = 12 * time.Hour
+
+// RetryAfterError asks the listener to deliver the message again after Delay instead of waiting for the
+// visibility timeout of the queue.
+type RetryAfterError struct {
+	Delay time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("retry after %s", e.Delay)
+	}
+	return fmt.Sprintf("retry after %s: %s", e.Delay, e.Err.Error())
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns an error that makes the listener redeliver the message after d.
+func RetryAfter(d time.Duration) error {
+	return &RetryAfterError{Delay: d}
+}
+
+// RedeliveryPolicy returns how long a message that failed should stay invisible, based on how many times it has
+// been received so far.
+type RedeliveryPolicy func(receiveCount int) time.Duration
+
+// ExponentialRedelivery doubles the delay with every receive, starting at base and capped at max.
+func ExponentialRedelivery(base, max time.Duration) RedeliveryPolicy {
+	return func(receiveCount int) time.Duration {
+		if receiveCount < 1 {
+			receiveCount = 1
+		}
+		delay := time.Duration(float64(base) * math.Exp2(float64(receiveCount-1)))
+		if delay > max || delay <= 0 {
+			return max
+		}
+		return delay
+	}
+}
+
+// scheduleRedelivery changes the visibility timeout of a failed message when the handler returned a
+// RetryAfterError or a redelivery policy is configured. Otherwise the message reappears after the visibility
+// timeout of the queue.
+func (l *SQSListener) scheduleRedelivery(message types.Message, handlerErr error) {
+	var delay time.Duration
+	var retryAfter *RetryAfterError
+	switch {
+	case errors.As(handlerErr, &retryAfter):
+		delay = retryAfter.Delay
+	case l.redeliveryPolicy != nil:
+		delay = l.redeliveryPolicy(receiveCount(message))
+	default:
+		return
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > MaxVisibilityTimeout {
+		delay = MaxVisibilityTimeout
+	}
+
+	ctx := context.Background()
+	_, err := l.sqsClient.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          l.queueURL,
+		ReceiptHandle:     message.ReceiptHandle,
+		VisibilityTimeout: int32(math.Ceil(delay.Seconds())),
+	})
+	if err != nil {
+		l.logger.Errorw("failed to schedule message redelivery",
+			"messageId", aws.ToString(message.MessageId),
+			"delay", delay,
+			"error", err,
+		)
+	}
+}
+
+func receiveCount(message types.Message) int {
+	count, err := strconv.Atoi(message.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+	if err != nil {
+		return 1
+	}
+	return count
+}
@@ -0,0 +1,111 @@
+package zaws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryAfterError(t *testing.T) {
+	t.Run("RetryAfter can be found with errors.As", func(t *testing.T) {
+		var retryAfter *RetryAfterError
+
+		ok := errors.As(RetryAfter(5*time.Second), &retryAfter)
+
+		assert.True(t, ok)
+		assert.Equal(t, 5*time.Second, retryAfter.Delay)
+	})
+
+	t.Run("RetryAfterError unwraps to its cause", func(t *testing.T) {
+		cause := errors.New("downstream unavailable")
+
+		err := error(&RetryAfterError{Delay: time.Second, Err: cause})
+
+		assert.ErrorIs(t, err, cause)
+		assert.Equal(t, "retry after 1s: downstream unavailable", err.Error())
+	})
+}
+
+func TestExponentialRedelivery(t *testing.T) {
+	policy := ExponentialRedelivery(10*time.Second, time.Minute)
+
+	assert.Equal(t, 10*time.Second, policy(1))
+	assert.Equal(t, 20*time.Second, policy(2))
+	assert.Equal(t, 40*time.Second, policy(3))
+	assert.Equal(t, time.Minute, policy(4))
+	assert.Equal(t, time.Minute, policy(100))
+}
+
+func TestSQSListener_scheduleRedelivery(t *testing.T) {
+	message := types.Message{
+		MessageId:     aws.String("test-id"),
+		ReceiptHandle: aws.String("test handle"),
+		Attributes: map[string]string{
+			string(types.MessageSystemAttributeNameApproximateReceiveCount): "3",
+		},
+	}
+
+	t.Run("scheduleRedelivery uses the delay of a RetryAfterError", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          sut.queueURL,
+				ReceiptHandle:     aws.String("test handle"),
+				VisibilityTimeout: 5,
+			}).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil)
+
+		sut.scheduleRedelivery(message, RetryAfter(5*time.Second))
+	})
+
+	t.Run("scheduleRedelivery uses the redelivery policy with the receive count of the message", func(t *testing.T) {
+		sut := getTestListener()
+		sut.redeliveryPolicy = ExponentialRedelivery(10*time.Second, time.Hour)
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          sut.queueURL,
+				ReceiptHandle:     aws.String("test handle"),
+				VisibilityTimeout: 40,
+			}).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil)
+
+		sut.scheduleRedelivery(message, errors.New("handler error"))
+	})
+
+	t.Run("scheduleRedelivery leaves the visibility timeout alone without a policy or RetryAfterError", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.EXPECT().ChangeMessageVisibility(gomock.Any(), gomock.Any()).Times(0)
+
+		sut.scheduleRedelivery(message, errors.New("handler error"))
+	})
+
+	t.Run("scheduleRedelivery caps the delay at the maximum visibility timeout", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          sut.queueURL,
+				ReceiptHandle:     aws.String("test handle"),
+				VisibilityTimeout: 43200,
+			}).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil)
+
+		sut.scheduleRedelivery(message, RetryAfter(24*time.Hour))
+	})
+}
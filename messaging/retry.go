@@ -42,16 +42,19 @@ func defaultRetry(function func() error, opts ...Option) error {
 		if err == nil || !errors.As(err, &throttledErr) {
 			break
 		}
-		waitDuration := config.WaitBase
-		if config.Backoff {
-			exp := math.Exp2(float64(i + 1))
-			waitDuration = time.Duration(float64(config.WaitBase) * exp)
-			waitDuration = waitDuration.Round(time.Millisecond)
-			random := rand.New(rand.NewSource(time.Now().UnixNano()))
-			waitDuration = time.Duration(float64(waitDuration) * random.Float64())
-		}
-
-		time.Sleep(waitDuration)
+		time.Sleep(config.waitDuration(i))
 	}
 	return err
 }
+
+// waitDuration returns how long to wait after the failed attempt i, counted from 0.
+func (c RetryConfig) waitDuration(i int) time.Duration {
+	if !c.Backoff {
+		return c.WaitBase
+	}
+	exp := math.Exp2(float64(i + 1))
+	waitDuration := time.Duration(float64(c.WaitBase) * exp)
+	waitDuration = waitDuration.Round(time.Millisecond)
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return time.Duration(float64(waitDuration) * random.Float64())
+}
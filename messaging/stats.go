@@ -0,0 +1,151 @@
+package zaws
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencySamples is how many of the most recent handler latencies the percentiles are computed from.
+const latencySamples = 1024
+
+// ListenerStats is a snapshot of what a listener is doing.
+type ListenerStats struct {
+	Received  int64
+	Succeeded int64
+	Failed    int64
+	Deleted   int64
+	// InFlight is the number of messages whose handler is running.
+	InFlight int64
+	// Abandoned counts the messages of handlers that were still running past their HandlerTimeout when the drain
+	// deadline passed. Their workers were freed without waiting for them.
+	Abandoned int64
+	// LatencyP50 and LatencyP95 are handler latency percentiles over the most recent messages. A batch handler
+	// call counts as one sample.
+	LatencyP50 time.Duration
+	LatencyP95 time.Duration
+	// LastSuccessfulPoll is zero until the first successful receive.
+	LastSuccessfulPoll    time.Time
+	ConsecutivePollErrors int64
+	// Pollers is the number of running poll loops. QueueDepth is the ApproximateNumberOfMessages read by the last
+	// scaling check and ScaleUps and ScaleDowns count the scaling decisions.
+	Pollers    int64
+	QueueDepth int64
+	ScaleUps   int64
+	ScaleDowns int64
+	// Paused is true between Pause and Resume.
+	Paused bool
+}
+
+type listenerCounters struct {
+	received           atomic.Int64
+	succeeded          atomic.Int64
+	failed             atomic.Int64
+	deleted            atomic.Int64
+	inFlight           atomic.Int64
+	abandoned          atomic.Int64
+	lastSuccessfulPoll atomic.Int64
+	pollers            atomic.Int64
+	queueDepth         atomic.Int64
+	scaleUps           atomic.Int64
+	scaleDowns         atomic.Int64
+	latencies          latencyWindow
+}
+
+// latencyWindow keeps the latest latencySamples handler latencies.
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (w *latencyWindow) record(latency time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.samples) < latencySamples {
+		w.samples = append(w.samples, latency)
+		return
+	}
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % latencySamples
+}
+
+func (w *latencyWindow) percentiles() (p50, p95 time.Duration) {
+	w.mu.Lock()
+	sorted := make([]time.Duration, len(w.samples))
+	copy(sorted, w.samples)
+	w.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0, 0
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return percentile(sorted, 50), percentile(sorted, 95)
+}
+
+// percentile uses the nearest-rank method on sorted samples.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Stats returns a snapshot of the message counters, handler latencies and receive health of the listener.
+func (l *SQSListener) Stats() ListenerStats {
+	p50, p95 := l.stats.latencies.percentiles()
+	var lastSuccessfulPoll time.Time
+	if nanos := l.stats.lastSuccessfulPoll.Load(); nanos != 0 {
+		lastSuccessfulPoll = time.Unix(0, nanos)
+	}
+
+	return ListenerStats{
+		Received:              l.stats.received.Load(),
+		Succeeded:             l.stats.succeeded.Load(),
+		Failed:                l.stats.failed.Load(),
+		Deleted:               l.stats.deleted.Load(),
+		InFlight:              l.stats.inFlight.Load(),
+		Abandoned:             l.stats.abandoned.Load(),
+		LatencyP50:            p50,
+		LatencyP95:            p95,
+		LastSuccessfulPoll:    lastSuccessfulPoll,
+		ConsecutivePollErrors: l.receiveFailures.Load(),
+		Pollers:               l.stats.pollers.Load(),
+		QueueDepth:            l.stats.queueDepth.Load(),
+		ScaleUps:              l.stats.scaleUps.Load(),
+		ScaleDowns:            l.stats.scaleDowns.Load(),
+		Paused:                l.control.isPaused(),
+	}
+}
+
+// logStats logs the stats of the listener every interval until ctx is done.
+func (l *SQSListener) logStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := l.Stats()
+			l.logger.Infow("listener stats",
+				"queue", l.queueName,
+				"received", stats.Received,
+				"succeeded", stats.Succeeded,
+				"failed", stats.Failed,
+				"deleted", stats.Deleted,
+				"inFlight", stats.InFlight,
+				"abandoned", stats.Abandoned,
+				"latencyP50", stats.LatencyP50,
+				"latencyP95", stats.LatencyP95,
+				"lastSuccessfulPoll", stats.LastSuccessfulPoll,
+				"consecutivePollErrors", stats.ConsecutivePollErrors,
+				"pollers", stats.Pollers,
+				"queueDepth", stats.QueueDepth,
+			)
+		}
+	}
+}
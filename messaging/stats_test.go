@@ -0,0 +1,93 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_latencyWindow(t *testing.T) {
+	t.Run("percentiles returns zero without samples", func(t *testing.T) {
+		var sut latencyWindow
+
+		p50, p95 := sut.percentiles()
+
+		assert.Zero(t, p50)
+		assert.Zero(t, p95)
+	})
+
+	t.Run("percentiles uses the nearest rank of the samples", func(t *testing.T) {
+		var sut latencyWindow
+		for i := 100; i >= 1; i-- {
+			sut.record(time.Duration(i) * time.Millisecond)
+		}
+
+		p50, p95 := sut.percentiles()
+
+		assert.Equal(t, 50*time.Millisecond, p50)
+		assert.Equal(t, 95*time.Millisecond, p95)
+	})
+
+	t.Run("record keeps only the most recent samples", func(t *testing.T) {
+		var sut latencyWindow
+		for i := 0; i < latencySamples; i++ {
+			sut.record(time.Hour)
+		}
+		for i := 0; i < latencySamples; i++ {
+			sut.record(time.Millisecond)
+		}
+
+		p50, p95 := sut.percentiles()
+
+		assert.Equal(t, time.Millisecond, p50)
+		assert.Equal(t, time.Millisecond, p95)
+	})
+}
+
+func TestSQSListener_Stats(t *testing.T) {
+	t.Run("Stats counts handled and deleted messages and the receive health", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.gracefulShutdownManager = nil
+		sut.maxNumberOfMessages = 2
+		sqsClient := NewMockISQSClient(ctrl)
+		handler := NewMockMessageHandler(ctrl)
+		sut.sqsClient = sqsClient
+		sut.handler = handler
+		ok := types.Message{MessageId: aws.String("ok"), ReceiptHandle: aws.String("ok handle")}
+		failing := types.Message{MessageId: aws.String("failing"), ReceiptHandle: aws.String("failing handle")}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		gomock.InOrder(
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{ok, failing}}, nil),
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).Return(nil, errors.New("receive error")),
+			sqsClient.EXPECT().ReceiveMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(context.Context, *sqs.ReceiveMessageInput, ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+				cancel()
+				return nil, context.Canceled
+			}),
+		)
+		handler.EXPECT().Handle(ok).Return(nil)
+		handler.EXPECT().Handle(failing).Return(errors.New("handler error"))
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil)
+		startedAt := time.Now()
+
+		_ = sut.Run(ctx)
+		stats := sut.Stats()
+
+		assert.Equal(t, int64(2), stats.Received)
+		assert.Equal(t, int64(1), stats.Succeeded)
+		assert.Equal(t, int64(1), stats.Failed)
+		assert.Equal(t, int64(1), stats.Deleted)
+		assert.Equal(t, int64(0), stats.InFlight)
+		assert.Equal(t, int64(1), stats.ConsecutivePollErrors)
+		assert.False(t, stats.LastSuccessfulPoll.Before(startedAt))
+	})
+}
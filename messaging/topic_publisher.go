@@ -3,28 +3,31 @@ package zaws
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go-v2/service/sns/types"
-
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 )
 
 type ITopicPublisher interface {
-	Publish(message string) error
-	PublishWithAttributes(message string, attributes map[string]string) error
-	PublishEvent(message string) error
-	PublishEventWithAttributes(message string, attributes map[string]string) error
+	Publish(message string, opts ...PublishOption) error
+	PublishWithAttributes(message string, attributes map[string]string, opts ...PublishOption) error
+	PublishEvent(message string, opts ...PublishOption) error
+	PublishEventWithAttributes(message string, attributes map[string]string, opts ...PublishOption) error
 	PublishWithRetry(message string, opts ...Option) error
 	PublishWithAttributesWithRetry(message string, attributes map[string]string, opts ...Option) error
 	PublishEventWithRetry(message string, opts ...Option) error
 	PublishEventWithAttributesWithRetry(message string, attributes map[string]string, opts ...Option) error
+	PublishCtx(ctx context.Context, message string, opts ...PublishOption) (PublishResult, error)
+	PublishWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error)
+	PublishEventCtx(ctx context.Context, message string, opts ...PublishOption) (PublishResult, error)
+	PublishEventWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error)
 }
 
 type TopicPublisher struct {
-	snsClient ISNSClient
-	topicName string
-	topicArn  string
+	snsClient  ISNSClient
+	topicName  string
+	topicArn   string
+	claimCheck ClaimCheckConfig
 }
 
 func NewTopicPublisher(region string, topicName string) (*TopicPublisher, error) {
@@ -55,66 +58,85 @@ func NewTopicPublisherWithConfig(cfg aws.Config, topicName string) (*TopicPublis
 	}, nil
 }
 
-func (p *TopicPublisher) Publish(message string) error {
-	ctx := context.Background()
-	_, err := p.snsClient.Publish(ctx, &sns.PublishInput{
-		Message:  aws.String(message),
-		TopicArn: aws.String(p.topicArn),
-	})
+// SetClaimCheck makes the publisher store message bodies above the threshold in a blob store, see ClaimCheckConfig.
+func (p *TopicPublisher) SetClaimCheck(config ClaimCheckConfig) {
+	p.claimCheck = config
+}
+
+func (p *TopicPublisher) Publish(message string, opts ...PublishOption) error {
+	_, err := p.PublishCtx(context.Background(), message, opts...)
 	return err
 }
 
-func (p *TopicPublisher) PublishWithAttributes(message string, attributes map[string]string) error {
-	ctx := context.Background()
-	messageAttributesMap := make(map[string]types.MessageAttributeValue)
-	for key, val := range attributes {
-		messageAttributesMap[key] = types.MessageAttributeValue{StringValue: &val}
-	}
-	_, err := p.snsClient.Publish(ctx, &sns.PublishInput{
-		Message:           aws.String(message),
-		TopicArn:          aws.String(p.topicArn),
-		MessageAttributes: messageAttributesMap,
-	})
+func (p *TopicPublisher) PublishWithAttributes(message string, attributes map[string]string, opts ...PublishOption) error {
+	_, err := p.PublishWithAttributesCtx(context.Background(), message, attributes, opts...)
 	return err
 }
 
-func (p *TopicPublisher) PublishEvent(message string) error {
-	ctx := context.Background()
-	_, err := p.snsClient.Publish(ctx, &sns.PublishInput{
-		Message:  aws.String(message),
-		TopicArn: aws.String(p.topicArn),
-		Subject:  aws.String(p.topicName),
-	})
+func (p *TopicPublisher) PublishEvent(message string, opts ...PublishOption) error {
+	_, err := p.PublishEventCtx(context.Background(), message, opts...)
 	return err
 }
 
-func (p *TopicPublisher) PublishEventWithAttributes(message string, attributes map[string]string) error {
-	ctx := context.Background()
-	messageAttributesMap := make(map[string]types.MessageAttributeValue)
-	for key, val := range attributes {
-		messageAttributesMap[key] = types.MessageAttributeValue{StringValue: &val}
+func (p *TopicPublisher) PublishEventWithAttributes(message string, attributes map[string]string, opts ...PublishOption) error {
+	_, err := p.PublishEventWithAttributesCtx(context.Background(), message, attributes, opts...)
+	return err
+}
+
+// PublishCtx publishes message to the topic and returns the ID SNS assigned to it.
+func (p *TopicPublisher) PublishCtx(ctx context.Context, message string, opts ...PublishOption) (PublishResult, error) {
+	return p.publish(ctx, message, nil, nil, opts)
+}
+
+func (p *TopicPublisher) PublishWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error) {
+	return p.publish(ctx, message, nil, attributes, opts)
+}
+
+// PublishEventCtx is PublishCtx with the name of the topic as the subject, see MultiTopicHandler.
+func (p *TopicPublisher) PublishEventCtx(ctx context.Context, message string, opts ...PublishOption) (PublishResult, error) {
+	return p.publish(ctx, message, aws.String(p.topicName), nil, opts)
+}
+
+func (p *TopicPublisher) PublishEventWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error) {
+	return p.publish(ctx, message, aws.String(p.topicName), attributes, opts)
+}
+
+func (p *TopicPublisher) publish(ctx context.Context, message string, subject *string, attributes map[string]string, opts []PublishOption) (PublishResult, error) {
+	options := newPublishOptions(opts)
+	message, claimCheckAttributes, err := p.claimCheck.offload(ctx, message)
+	if err != nil {
+		return PublishResult{}, err
 	}
-	_, err := p.snsClient.Publish(ctx, &sns.PublishInput{
-		Message:           aws.String(message),
-		TopicArn:          aws.String(p.topicArn),
-		Subject:           aws.String(p.topicName),
-		MessageAttributes: messageAttributesMap,
+	result, err := p.snsClient.Publish(ctx, &sns.PublishInput{
+		Message:                aws.String(message),
+		TopicArn:               aws.String(p.topicArn),
+		Subject:                subject,
+		MessageAttributes:      StringAttributes(attributes).with(options.attributes).with(claimCheckAttributes).sns(),
+		MessageGroupId:         options.messageGroupID,
+		MessageDeduplicationId: options.deduplicationID,
 	})
-	return err
+	if err != nil {
+		return PublishResult{}, err
+	}
+	return PublishResult{MessageID: aws.ToString(result.MessageId), SequenceNumber: aws.ToString(result.SequenceNumber)}, nil
 }
 
 func (p *TopicPublisher) PublishWithRetry(message string, opts ...Option) error {
-	return Retry(p.Publish, message, opts...)
+	return Retry(func(message string) error { return p.Publish(message) }, message, opts...)
 }
 
 func (p *TopicPublisher) PublishWithAttributesWithRetry(message string, attributes map[string]string, opts ...Option) error {
-	return RetryWithAttributes(p.PublishWithAttributes, message, attributes, opts...)
+	return RetryWithAttributes(func(message string, attributes map[string]string) error {
+		return p.PublishWithAttributes(message, attributes)
+	}, message, attributes, opts...)
 }
 
 func (p *TopicPublisher) PublishEventWithRetry(message string, opts ...Option) error {
-	return Retry(p.PublishEvent, message, opts...)
+	return Retry(func(message string) error { return p.PublishEvent(message) }, message, opts...)
 }
 
 func (p *TopicPublisher) PublishEventWithAttributesWithRetry(message string, attributes map[string]string, opts ...Option) error {
-	return RetryWithAttributes(p.PublishEventWithAttributes, message, attributes, opts...)
+	return RetryWithAttributes(func(message string, attributes map[string]string) error {
+		return p.PublishEventWithAttributes(message, attributes)
+	}, message, attributes, opts...)
 }
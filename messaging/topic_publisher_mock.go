@@ -5,6 +5,7 @@
 package zaws
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,45 +35,120 @@ func (m *MockITopicPublisher) EXPECT() *MockITopicPublisherMockRecorder {
 }
 
 // Publish mocks base method.
-func (m *MockITopicPublisher) Publish(message string) error {
+func (m *MockITopicPublisher) Publish(message string, opts ...PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Publish", message)
+	varargs := []interface{}{message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Publish", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Publish indicates an expected call of Publish.
-func (mr *MockITopicPublisherMockRecorder) Publish(message interface{}) *gomock.Call {
+func (mr *MockITopicPublisherMockRecorder) Publish(message interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockITopicPublisher)(nil).Publish), varargs...)
+}
+
+// PublishCtx mocks base method.
+func (m *MockITopicPublisher) PublishCtx(ctx context.Context, message string, opts ...PublishOption) (PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishCtx", varargs...)
+	ret0, _ := ret[0].(PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishCtx indicates an expected call of PublishCtx.
+func (mr *MockITopicPublisherMockRecorder) PublishCtx(ctx, message interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockITopicPublisher)(nil).Publish), message)
+	varargs := append([]interface{}{ctx, message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishCtx", reflect.TypeOf((*MockITopicPublisher)(nil).PublishCtx), varargs...)
 }
 
 // PublishEvent mocks base method.
-func (m *MockITopicPublisher) PublishEvent(message string) error {
+func (m *MockITopicPublisher) PublishEvent(message string, opts ...PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishEvent", message)
+	varargs := []interface{}{message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishEvent", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PublishEvent indicates an expected call of PublishEvent.
-func (mr *MockITopicPublisherMockRecorder) PublishEvent(message interface{}) *gomock.Call {
+func (mr *MockITopicPublisherMockRecorder) PublishEvent(message interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEvent", reflect.TypeOf((*MockITopicPublisher)(nil).PublishEvent), varargs...)
+}
+
+// PublishEventCtx mocks base method.
+func (m *MockITopicPublisher) PublishEventCtx(ctx context.Context, message string, opts ...PublishOption) (PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, message}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishEventCtx", varargs...)
+	ret0, _ := ret[0].(PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishEventCtx indicates an expected call of PublishEventCtx.
+func (mr *MockITopicPublisherMockRecorder) PublishEventCtx(ctx, message interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEvent", reflect.TypeOf((*MockITopicPublisher)(nil).PublishEvent), message)
+	varargs := append([]interface{}{ctx, message}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEventCtx", reflect.TypeOf((*MockITopicPublisher)(nil).PublishEventCtx), varargs...)
 }
 
 // PublishEventWithAttributes mocks base method.
-func (m *MockITopicPublisher) PublishEventWithAttributes(message string, attributes map[string]string) error {
+func (m *MockITopicPublisher) PublishEventWithAttributes(message string, attributes map[string]string, opts ...PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishEventWithAttributes", message, attributes)
+	varargs := []interface{}{message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishEventWithAttributes", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PublishEventWithAttributes indicates an expected call of PublishEventWithAttributes.
-func (mr *MockITopicPublisherMockRecorder) PublishEventWithAttributes(message, attributes interface{}) *gomock.Call {
+func (mr *MockITopicPublisherMockRecorder) PublishEventWithAttributes(message, attributes interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEventWithAttributes", reflect.TypeOf((*MockITopicPublisher)(nil).PublishEventWithAttributes), varargs...)
+}
+
+// PublishEventWithAttributesCtx mocks base method.
+func (m *MockITopicPublisher) PublishEventWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishEventWithAttributesCtx", varargs...)
+	ret0, _ := ret[0].(PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishEventWithAttributesCtx indicates an expected call of PublishEventWithAttributesCtx.
+func (mr *MockITopicPublisherMockRecorder) PublishEventWithAttributesCtx(ctx, message, attributes interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEventWithAttributes", reflect.TypeOf((*MockITopicPublisher)(nil).PublishEventWithAttributes), message, attributes)
+	varargs := append([]interface{}{ctx, message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishEventWithAttributesCtx", reflect.TypeOf((*MockITopicPublisher)(nil).PublishEventWithAttributesCtx), varargs...)
 }
 
 // PublishEventWithAttributesWithRetry mocks base method.
@@ -114,17 +190,42 @@ func (mr *MockITopicPublisherMockRecorder) PublishEventWithRetry(message interfa
 }
 
 // PublishWithAttributes mocks base method.
-func (m *MockITopicPublisher) PublishWithAttributes(message string, attributes map[string]string) error {
+func (m *MockITopicPublisher) PublishWithAttributes(message string, attributes map[string]string, opts ...PublishOption) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishWithAttributes", message, attributes)
+	varargs := []interface{}{message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishWithAttributes", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PublishWithAttributes indicates an expected call of PublishWithAttributes.
-func (mr *MockITopicPublisherMockRecorder) PublishWithAttributes(message, attributes interface{}) *gomock.Call {
+func (mr *MockITopicPublisherMockRecorder) PublishWithAttributes(message, attributes interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributes", reflect.TypeOf((*MockITopicPublisher)(nil).PublishWithAttributes), varargs...)
+}
+
+// PublishWithAttributesCtx mocks base method.
+func (m *MockITopicPublisher) PublishWithAttributesCtx(ctx context.Context, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, message, attributes}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishWithAttributesCtx", varargs...)
+	ret0, _ := ret[0].(PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishWithAttributesCtx indicates an expected call of PublishWithAttributesCtx.
+func (mr *MockITopicPublisherMockRecorder) PublishWithAttributesCtx(ctx, message, attributes interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributes", reflect.TypeOf((*MockITopicPublisher)(nil).PublishWithAttributes), message, attributes)
+	varargs := append([]interface{}{ctx, message, attributes}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishWithAttributesCtx", reflect.TypeOf((*MockITopicPublisher)(nil).PublishWithAttributesCtx), varargs...)
 }
 
 // PublishWithAttributesWithRetry mocks base method.
@@ -6,8 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/ammyy9908/go-common-libraries/messaging/mock"
-
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sns/types"
@@ -99,7 +97,7 @@ func TestTopicPublisher_PublishWithAttributes(t *testing.T) {
 				Message:  aws.String("test message"),
 				TopicArn: aws.String(topicArn),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &DummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &DummyUUID},
 				},
 			}).
 			Return(&sns.PublishOutput{}, nil)
@@ -119,7 +117,7 @@ func TestTopicPublisher_PublishWithAttributes(t *testing.T) {
 				Message:  aws.String("test message"),
 				TopicArn: aws.String(topicArn),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &dummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &dummyUUID},
 				}}).
 			Return(&sns.PublishOutput{}, errors.New("test error"))
 
@@ -178,7 +176,7 @@ func TestTopicPublisher_PublishEventWithAttributes(t *testing.T) {
 				TopicArn: aws.String(topicArn),
 				Subject:  aws.String(topicName),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &DummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &DummyUUID},
 				}}).
 			Return(&sns.PublishOutput{}, nil)
 
@@ -198,7 +196,7 @@ func TestTopicPublisher_PublishEventWithAttributes(t *testing.T) {
 				TopicArn: aws.String(topicArn),
 				Subject:  aws.String(topicName),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &dummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &dummyUUID},
 				}}).
 			Return(&sns.PublishOutput{}, errors.New("test error"))
 
@@ -208,6 +206,27 @@ func TestTopicPublisher_PublishEventWithAttributes(t *testing.T) {
 
 		assert.NotNil(t, err)
 	})
+
+	t.Run("PublishEventWithAttributes sends the typed attributes of WithAttributes", func(t *testing.T) {
+		snsClient.
+			EXPECT().
+			Publish(ctx, &sns.PublishInput{
+				Message:  aws.String("test message"),
+				TopicArn: aws.String(topicArn),
+				Subject:  aws.String(topicName),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"first":  {DataType: aws.String("String"), StringValue: aws.String("1")},
+					"second": {DataType: aws.String("String"), StringValue: aws.String("2")},
+					"amount": {DataType: aws.String("Number"), StringValue: aws.String("12.5")},
+					"tags":   {DataType: aws.String("String.Array"), StringValue: aws.String(`["new"]`)},
+				}}).
+			Return(&sns.PublishOutput{}, nil)
+
+		err := publisher.PublishEventWithAttributes("test message", map[string]string{"first": "1", "second": "2"},
+			WithAttributes(NewAttributes().Float("amount", 12.5).StringArray("tags", []string{"new"})))
+
+		assert.Nil(t, err)
+	})
 }
 
 func TestTopicPublisher_PublishWithRetry(t *testing.T) {
@@ -351,9 +370,9 @@ func TestTopicPublisher_PublishEventWithAttributesWithRetry(t *testing.T) {
 	})
 }
 
-func publisherTestSetup(t *testing.T) (*mock.MockISNSClient, *TopicPublisher, string, string) {
+func publisherTestSetup(t *testing.T) (*MockISNSClient, *TopicPublisher, string, string) {
 	t.Helper()
-	snsClient := mock.NewMockISNSClient(gomock.NewController(t))
+	snsClient := NewMockISNSClient(gomock.NewController(t))
 	topicName := "test-topic"
 	topicArn := "arn:test-topic"
 
@@ -367,7 +386,7 @@ func publisherTestSetup(t *testing.T) (*mock.MockISNSClient, *TopicPublisher, st
 
 }
 
-func repeatThrottlingErrorSetup(t *testing.T, snsClient *mock.MockISNSClient, publisher *TopicPublisher, expectedErrorMessage error, withAttributes bool) {
+func repeatThrottlingErrorSetup(t *testing.T, snsClient *MockISNSClient, publisher *TopicPublisher, expectedErrorMessage error, withAttributes bool) {
 	ctx := context.Background()
 	t.Helper()
 	if withAttributes {
@@ -377,7 +396,7 @@ func repeatThrottlingErrorSetup(t *testing.T, snsClient *mock.MockISNSClient, pu
 				Message:  aws.String("test message"),
 				TopicArn: aws.String(publisher.topicArn),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &DummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &DummyUUID},
 				}}).
 			Return(&sns.PublishOutput{}, &types.ThrottledException{}).Times(2)
 
@@ -387,7 +406,7 @@ func repeatThrottlingErrorSetup(t *testing.T, snsClient *mock.MockISNSClient, pu
 				Message:  aws.String("test message"),
 				TopicArn: aws.String(publisher.topicArn),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &DummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &DummyUUID},
 				}}).
 			Return(&sns.PublishOutput{}, expectedErrorMessage)
 
@@ -410,7 +429,7 @@ func repeatThrottlingErrorSetup(t *testing.T, snsClient *mock.MockISNSClient, pu
 	}
 }
 
-func repeatEventThrottlingErrorSetup(t *testing.T, snsClient *mock.MockISNSClient, publisher *TopicPublisher, topicName string, expectedErrorMessage error, withAttributes bool) {
+func repeatEventThrottlingErrorSetup(t *testing.T, snsClient *MockISNSClient, publisher *TopicPublisher, topicName string, expectedErrorMessage error, withAttributes bool) {
 	t.Helper()
 	ctx := context.Background()
 	if withAttributes {
@@ -421,7 +440,7 @@ func repeatEventThrottlingErrorSetup(t *testing.T, snsClient *mock.MockISNSClien
 				TopicArn: aws.String(publisher.topicArn),
 				Subject:  aws.String(topicName),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &DummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &DummyUUID},
 				}}).
 			Return(&sns.PublishOutput{}, &types.ThrottledException{}).Times(2)
 
@@ -432,7 +451,7 @@ func repeatEventThrottlingErrorSetup(t *testing.T, snsClient *mock.MockISNSClien
 				TopicArn: aws.String(publisher.topicArn),
 				Subject:  aws.String(topicName),
 				MessageAttributes: map[string]types.MessageAttributeValue{
-					"X-Correlation-Id": {StringValue: &DummyUUID},
+					"X-Correlation-Id": {DataType: aws.String("String"), StringValue: &DummyUUID},
 				}}).
 			Return(&sns.PublishOutput{}, expectedErrorMessage)
 	} else {
@@ -455,3 +474,36 @@ func repeatEventThrottlingErrorSetup(t *testing.T, snsClient *mock.MockISNSClien
 			Return(&sns.PublishOutput{}, expectedErrorMessage)
 	}
 }
+
+func TestTopicPublisher_PublishEventCtx(t *testing.T) {
+	snsClient, publisher, topicName, topicArn := publisherTestSetup(t)
+	type contextKey struct{}
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+	t.Run("PublishEventCtx publishes the event with the given context and returns its message ID", func(t *testing.T) {
+		snsClient.
+			EXPECT().
+			Publish(ctx, &sns.PublishInput{
+				Message:  aws.String("test message"),
+				TopicArn: aws.String(topicArn),
+				Subject:  aws.String(topicName),
+			}).
+			Return(&sns.PublishOutput{MessageId: aws.String("test-id")}, nil)
+
+		result, err := publisher.PublishEventCtx(ctx, "test message")
+
+		assert.Nil(t, err)
+		assert.Equal(t, PublishResult{MessageID: "test-id"}, result)
+	})
+
+	t.Run("PublishCtx returns the error of the publishing", func(t *testing.T) {
+		snsClient.
+			EXPECT().
+			Publish(ctx, gomock.Any()).
+			Return(nil, errors.New("test error"))
+
+		_, err := publisher.PublishCtx(ctx, "test message")
+
+		assert.NotNil(t, err)
+	})
+}
@@ -41,9 +41,13 @@ func getTopicArn(snsClient ISNSClient, topicName string) (string, error) {
 	ctx := context.Background()
 	// No method for just getting topic ARN
 	// Create topic is idempotent, will return result if the topic exists
-	result, err := snsClient.CreateTopic(ctx, &sns.CreateTopicInput{
+	input := &sns.CreateTopicInput{
 		Name: aws.String(topicName),
-	})
+	}
+	if IsFifo(topicName) {
+		input.Attributes = map[string]string{"FifoTopic": "true"}
+	}
+	result, err := snsClient.CreateTopic(ctx, input)
 	if err != nil {
 		return "", err
 	}
@@ -6,8 +6,6 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/ammyy9908/go-common-libraries/messaging/mock"
-
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/aws/smithy-go/middleware"
 
@@ -22,7 +20,7 @@ func TestGetTopicArn(t *testing.T) {
 	ctx := context.Background()
 	t.Run("getTopicArn returns topic arn when given a topic name", func(t *testing.T) {
 		topicName := "test-topic"
-		snsClient := mock.NewMockISNSClient(gomock.NewController(t))
+		snsClient := NewMockISNSClient(gomock.NewController(t))
 		snsClient.
 			EXPECT().
 			CreateTopic(ctx, &sns.CreateTopicInput{
@@ -40,7 +38,7 @@ func TestGetTopicArn(t *testing.T) {
 
 func Test_setTopicPolicy(t *testing.T) {
 	ctx := context.Background()
-	sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
 	queueURL := "www.test-topic.com"
 	t.Run("setTopicPolicy sets queue policy and does not return an error when setting up policy", func(t *testing.T) {
 		sqsClient.
@@ -73,7 +71,7 @@ func Test_setTopicPolicy(t *testing.T) {
 
 func Test_getPolicyContent(t *testing.T) {
 	ctx := context.Background()
-	sqsClient := mock.NewMockISQSClient(gomock.NewController(t))
+	sqsClient := NewMockISQSClient(gomock.NewController(t))
 	queueURL := "www.test-topic.com"
 	t.Run("getPolicyContent returns a formatted policy given queue and subscription arn", func(t *testing.T) {
 		sqsClient.
@@ -2,8 +2,7 @@ package zaws
 
 import (
 	"context"
-
-	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
@@ -11,15 +10,21 @@ import (
 )
 
 type ITopicsPublisher interface {
-	Publish(topicName, message string) error
-	PublishWithAttributes(topicName, message string, attributes map[string]string) error
-	PublishEvent(topicName, subject, message string) error
-	PublishEventWithAttributes(topicName, subject, message string, attributes map[string]string) error
+	Publish(topicName, message string, opts ...PublishOption) error
+	PublishWithAttributes(topicName, message string, attributes map[string]string, opts ...PublishOption) error
+	PublishEvent(topicName, subject, message string, opts ...PublishOption) error
+	PublishEventWithAttributes(topicName, subject, message string, attributes map[string]string, opts ...PublishOption) error
+	PublishCtx(ctx context.Context, topicName, message string, opts ...PublishOption) (PublishResult, error)
+	PublishWithAttributesCtx(ctx context.Context, topicName, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error)
+	PublishEventCtx(ctx context.Context, topicName, subject, message string, opts ...PublishOption) (PublishResult, error)
+	PublishEventWithAttributesCtx(ctx context.Context, topicName, subject, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error)
 }
 
 type TopicsPublisher struct {
 	snsClient   ISNSClient
+	topicsMu    sync.Mutex
 	topicsCache map[string]string
+	claimCheck  ClaimCheckConfig
 }
 
 func NewTopicsPublisher(region string) (*TopicsPublisher, error) {
@@ -41,92 +46,92 @@ func NewTopicsPublisherWithConfig(cfg aws.Config) (*TopicsPublisher, error) {
 	}, nil
 }
 
-func (p *TopicsPublisher) Publish(topicName, message string) error {
-	ctx := context.Background()
-	topicArn, err := p.getTopicArn(topicName)
-	if err != nil {
-		return err
-	}
+// SetClaimCheck makes the publisher store message bodies above the threshold in a blob store, see ClaimCheckConfig.
+func (p *TopicsPublisher) SetClaimCheck(config ClaimCheckConfig) {
+	p.claimCheck = config
+}
 
-	_, err = p.snsClient.Publish(ctx, &sns.PublishInput{
-		Message:  aws.String(message),
-		TopicArn: aws.String(topicArn),
-	})
+func (p *TopicsPublisher) Publish(topicName, message string, opts ...PublishOption) error {
+	_, err := p.PublishCtx(context.Background(), topicName, message, opts...)
+	return err
+}
 
-	if err != nil {
-		return err
-	}
-	return nil
+func (p *TopicsPublisher) PublishWithAttributes(topicName, message string, attributes map[string]string, opts ...PublishOption) error {
+	_, err := p.PublishWithAttributesCtx(context.Background(), topicName, message, attributes, opts...)
+	return err
 }
 
-func (p *TopicsPublisher) PublishWithAttributes(topicName, message string, attributes map[string]string) error {
-	ctx := context.Background()
-	topicArn, err := p.getTopicArn(topicName)
-	if err != nil {
-		return err
-	}
-	messageAttributesMap := make(map[string]types.MessageAttributeValue)
-	for key, val := range attributes {
-		messageAttributesMap[key] = types.MessageAttributeValue{StringValue: &val}
-	}
+func (p *TopicsPublisher) PublishEvent(topicName, subject, message string, opts ...PublishOption) error {
+	_, err := p.PublishEventCtx(context.Background(), topicName, subject, message, opts...)
+	return err
+}
 
-	_, err = p.snsClient.Publish(ctx, &sns.PublishInput{
-		Message:           aws.String(message),
-		TopicArn:          aws.String(topicArn),
-		MessageAttributes: messageAttributesMap,
-	})
+func (p *TopicsPublisher) PublishEventWithAttributes(topicName, subject, message string, attributes map[string]string, opts ...PublishOption) error {
+	_, err := p.PublishEventWithAttributesCtx(context.Background(), topicName, subject, message, attributes, opts...)
+	return err
+}
 
-	if err != nil {
-		return err
-	}
-	return nil
+// PublishCtx publishes message to topicName and returns the ID SNS assigned to it.
+func (p *TopicsPublisher) PublishCtx(ctx context.Context, topicName, message string, opts ...PublishOption) (PublishResult, error) {
+	return p.publish(ctx, topicName, message, nil, nil, opts)
 }
 
-func (p *TopicsPublisher) PublishEvent(topicName, subject, message string) error {
-	ctx := context.Background()
-	topicArn, err := p.getTopicArn(topicName)
-	if err != nil {
-		return err
-	}
+func (p *TopicsPublisher) PublishWithAttributesCtx(ctx context.Context, topicName, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error) {
+	return p.publish(ctx, topicName, message, nil, attributes, opts)
+}
 
-	_, err = p.snsClient.Publish(ctx, &sns.PublishInput{
-		Message:  aws.String(message),
-		TopicArn: aws.String(topicArn),
-		Subject:  aws.String(subject),
-	})
-	return err
+func (p *TopicsPublisher) PublishEventCtx(ctx context.Context, topicName, subject, message string, opts ...PublishOption) (PublishResult, error) {
+	return p.publish(ctx, topicName, message, aws.String(subject), nil, opts)
 }
 
-func (p *TopicsPublisher) PublishEventWithAttributes(topicName, subject, message string, attributes map[string]string) error {
-	ctx := context.Background()
-	topicArn, err := p.getTopicArn(topicName)
+func (p *TopicsPublisher) PublishEventWithAttributesCtx(ctx context.Context, topicName, subject, message string, attributes map[string]string, opts ...PublishOption) (PublishResult, error) {
+	return p.publish(ctx, topicName, message, aws.String(subject), attributes, opts)
+}
+
+func (p *TopicsPublisher) publish(ctx context.Context, topicName, message string, subject *string, attributes map[string]string, opts []PublishOption) (PublishResult, error) {
+	options := newPublishOptions(opts)
+	message, claimCheckAttributes, err := p.claimCheck.offload(ctx, message)
 	if err != nil {
-		return err
+		return PublishResult{}, err
 	}
-	messageAttributesMap := make(map[string]types.MessageAttributeValue)
-	for key, val := range attributes {
-		messageAttributesMap[key] = types.MessageAttributeValue{StringValue: &val}
+	topicArn, err := p.getTopicArn(topicName)
+	if err != nil {
+		return PublishResult{}, err
 	}
 
-	_, err = p.snsClient.Publish(ctx, &sns.PublishInput{
-		Message:           aws.String(message),
-		TopicArn:          aws.String(topicArn),
-		Subject:           aws.String(subject),
-		MessageAttributes: messageAttributesMap,
+	result, err := p.snsClient.Publish(ctx, &sns.PublishInput{
+		Message:                aws.String(message),
+		TopicArn:               aws.String(topicArn),
+		Subject:                subject,
+		MessageAttributes:      StringAttributes(attributes).with(options.attributes).with(claimCheckAttributes).sns(),
+		MessageGroupId:         options.messageGroupID,
+		MessageDeduplicationId: options.deduplicationID,
 	})
-	return err
+	if err != nil {
+		return PublishResult{}, err
+	}
+	return PublishResult{MessageID: aws.ToString(result.MessageId), SequenceNumber: aws.ToString(result.SequenceNumber)}, nil
 }
 
+// getTopicArn resolves topicName once and caches the ARN. It is safe for concurrent use, e.g. by the workers of an
+// AsyncPublisher.
 func (p *TopicsPublisher) getTopicArn(topicName string) (string, error) {
+	p.topicsMu.Lock()
 	topicArn, ok := p.topicsCache[topicName]
-	if !ok {
-		resultArn, err := getTopicArn(p.snsClient, topicName)
-		if err != nil {
-			return "", err
-		}
-		p.topicsCache[topicName] = resultArn
-		topicArn = resultArn
+	p.topicsMu.Unlock()
+	if ok {
+		return topicArn, nil
 	}
 
+	topicArn, err := getTopicArn(p.snsClient, topicName)
+	if err != nil {
+		return "", err
+	}
+	p.topicsMu.Lock()
+	defer p.topicsMu.Unlock()
+	if p.topicsCache == nil {
+		p.topicsCache = make(map[string]string)
+	}
+	p.topicsCache[topicName] = topicArn
 	return topicArn, nil
 }
@@ -5,8 +5,6 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/ammyy9908/go-common-libraries/messaging/mock"
-
 	"github.com/aws/aws-sdk-go-v2/service/sns/types"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -15,10 +13,10 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func setup(t *testing.T) (*mock.MockISNSClient, *TopicsPublisher, string) {
+func setup(t *testing.T) (*MockISNSClient, *TopicsPublisher, string) {
 	t.Helper()
 
-	snsClient := mock.NewMockISNSClient(gomock.NewController(t))
+	snsClient := NewMockISNSClient(gomock.NewController(t))
 
 	topicsCache := make(map[string]string)
 	testTopic := "test-topic-1"
@@ -312,7 +310,7 @@ func TestTopicsPublisher_PublishWithAttributes(t *testing.T) {
 	testAttribute := "test-value"
 	inputAttributes := map[string]string{"test-key": "test-value"}
 	messageAttributesMap := make(map[string]types.MessageAttributeValue)
-	messageAttributesMap["test-key"] = types.MessageAttributeValue{StringValue: &testAttribute}
+	messageAttributesMap["test-key"] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: &testAttribute}
 
 	t.Run("PublishWithAttributes sends message and does not return an error", func(t *testing.T) {
 		snsClient, publisher, topicName := setup(t)
@@ -369,7 +367,7 @@ func TestTopicsPublisher_PublishEventWithAttributes(t *testing.T) {
 	testSubject := "test-subject"
 	inputAttributes := map[string]string{"test-key": "test-value"}
 	messageAttributesMap := make(map[string]types.MessageAttributeValue)
-	messageAttributesMap["test-key"] = types.MessageAttributeValue{StringValue: &testAttribute}
+	messageAttributesMap["test-key"] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: &testAttribute}
 
 	t.Run("PublishWithAttributes sends message and does not return an error", func(t *testing.T) {
 		snsClient, publisher, topicName := setup(t)
@@ -421,3 +419,53 @@ func TestTopicsPublisher_PublishEventWithAttributes(t *testing.T) {
 		assert.Equal(t, testError, err)
 	})
 }
+
+func TestTopicsPublisher_PublishCtx(t *testing.T) {
+	type contextKey struct{}
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+	t.Run("PublishCtx publishes with the given context and returns the message ID and sequence number", func(t *testing.T) {
+		snsClient, publisher, testTopic := setup(t)
+		snsClient.
+			EXPECT().
+			Publish(ctx, &sns.PublishInput{
+				Message:  aws.String("test message"),
+				TopicArn: aws.String("test-arn"),
+			}).
+			Return(&sns.PublishOutput{MessageId: aws.String("test-id"), SequenceNumber: aws.String("1")}, nil)
+
+		result, err := publisher.PublishCtx(ctx, testTopic, "test message")
+
+		assert.Nil(t, err)
+		assert.Equal(t, PublishResult{MessageID: "test-id", SequenceNumber: "1"}, result)
+	})
+
+	t.Run("PublishEventWithAttributesCtx returns the error of the publishing", func(t *testing.T) {
+		snsClient, publisher, testTopic := setup(t)
+		snsClient.
+			EXPECT().
+			Publish(ctx, gomock.Any()).
+			Return(nil, errors.New("test error"))
+
+		_, err := publisher.PublishEventWithAttributesCtx(ctx, testTopic, "subject", "test message", map[string]string{"key": "value"})
+
+		assert.NotNil(t, err)
+	})
+	t.Run("PublishCtx sends the typed attributes of WithAttributes", func(t *testing.T) {
+		snsClient, publisher, testTopic := setup(t)
+		snsClient.
+			EXPECT().
+			Publish(ctx, &sns.PublishInput{
+				Message:  aws.String("test message"),
+				TopicArn: aws.String("test-arn"),
+				MessageAttributes: map[string]types.MessageAttributeValue{
+					"payload": {DataType: aws.String("Binary"), BinaryValue: []byte{1}},
+				},
+			}).
+			Return(&sns.PublishOutput{}, nil)
+
+		_, err := publisher.PublishCtx(ctx, testTopic, "test message", WithAttributes(NewAttributes().Binary("payload", []byte{1})))
+
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,79 @@
+package zaws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goccy/go-json"
+)
+
+// Validator is implemented by events that check their own content. Register calls Validate after decoding and
+// fails the message permanently when it returns an error.
+type Validator interface {
+	Validate() error
+}
+
+// Register registers a handler for subject that receives the message decoded from JSON into T. Messages that cannot
+// be decoded or fail validation are permanent failures.
+func Register[T any](h *MultiTopicHandler, subject string, handler func(ctx context.Context, event T) error) {
+	h.RegisterContextHandler(subject, func(ctx context.Context, message string) error {
+		event, err := decodeEvent[T](message)
+		if err != nil {
+			return Permanent(fmt.Errorf("invalid %s event: %w", subject, err))
+		}
+		return handler(ctx, event)
+	})
+}
+
+func decodeEvent[T any](message string) (T, error) {
+	var event T
+	err := json.Unmarshal([]byte(message), &event)
+	if err != nil {
+		return event, err
+	}
+
+	validator, ok := any(event).(Validator)
+	if !ok {
+		validator, ok = any(&event).(Validator)
+	}
+	if ok {
+		err = validator.Validate()
+	}
+	return event, err
+}
+
+// EventPublisher publishes events of type T as JSON to a topic, for handlers registered with Register under the
+// name of the topic.
+type EventPublisher[T any] struct {
+	publisher ITopicPublisher
+}
+
+func NewEventPublisher[T any](publisher ITopicPublisher) *EventPublisher[T] {
+	return &EventPublisher[T]{publisher: publisher}
+}
+
+func (p *EventPublisher[T]) Publish(event T, opts ...PublishOption) error {
+	_, err := p.PublishCtx(context.Background(), event, opts...)
+	return err
+}
+
+func (p *EventPublisher[T]) PublishWithAttributes(event T, attributes map[string]string, opts ...PublishOption) error {
+	_, err := p.PublishWithAttributesCtx(context.Background(), event, attributes, opts...)
+	return err
+}
+
+func (p *EventPublisher[T]) PublishCtx(ctx context.Context, event T, opts ...PublishOption) (PublishResult, error) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return PublishResult{}, err
+	}
+	return p.publisher.PublishEventCtx(ctx, string(message), opts...)
+}
+
+func (p *EventPublisher[T]) PublishWithAttributesCtx(ctx context.Context, event T, attributes map[string]string, opts ...PublishOption) (PublishResult, error) {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return PublishResult{}, err
+	}
+	return p.publisher.PublishEventWithAttributesCtx(ctx, string(message), attributes, opts...)
+}
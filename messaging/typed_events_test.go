@@ -0,0 +1,96 @@
+package zaws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type testOrderEvent struct {
+	ID     string `json:"id"`
+	Amount int    `json:"amount"`
+}
+
+func (e testOrderEvent) Validate() error {
+	if e.ID == "" {
+		return errors.New("missing id")
+	}
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	type contextKey struct{}
+	mtH := NewMultiTopicHandler()
+	var received testOrderEvent
+	var receivedValue interface{}
+	Register(mtH, "orders", func(ctx context.Context, event testOrderEvent) error {
+		received = event
+		receivedValue = ctx.Value(contextKey{})
+		return nil
+	})
+	ctx := context.WithValue(context.Background(), contextKey{}, "value")
+
+	t.Run("Register decodes the message and passes the context to the handler", func(t *testing.T) {
+		message := types.Message{Body: aws.String(`{"subject":"orders","message":"{\"id\":\"1\",\"amount\":5}"}`)}
+
+		err := mtH.HandleContext(ctx, message)
+
+		assert.Nil(t, err)
+		assert.Equal(t, testOrderEvent{ID: "1", Amount: 5}, received)
+		assert.Equal(t, "value", receivedValue)
+	})
+
+	t.Run("Register fails permanently when the message cannot be decoded", func(t *testing.T) {
+		message := types.Message{Body: aws.String(`{"subject":"orders","message":"not json"}`)}
+
+		err := mtH.HandleContext(ctx, message)
+
+		assert.True(t, IsPermanent(err))
+	})
+
+	t.Run("Register fails permanently when the event is not valid", func(t *testing.T) {
+		message := types.Message{Body: aws.String(`{"subject":"orders","message":"{\"amount\":5}"}`)}
+
+		err := mtH.HandleContext(ctx, message)
+
+		assert.True(t, IsPermanent(err))
+		assert.ErrorContains(t, err, "missing id")
+	})
+
+	t.Run("Register returns the error of the handler", func(t *testing.T) {
+		handlerErr := errors.New("handler error")
+		Register(mtH, "failing", func(context.Context, testOrderEvent) error { return handlerErr })
+		message := types.Message{Body: aws.String(`{"subject":"failing","message":"{\"id\":\"1\"}"}`)}
+
+		err := mtH.HandleContext(ctx, message)
+
+		assert.Equal(t, handlerErr, err)
+	})
+}
+
+func TestEventPublisher(t *testing.T) {
+	topicPublisher := NewMockITopicPublisher(gomock.NewController(t))
+	publisher := NewEventPublisher[testOrderEvent](topicPublisher)
+
+	t.Run("Publish publishes the event as JSON", func(t *testing.T) {
+		topicPublisher.EXPECT().PublishEventCtx(context.Background(), `{"id":"1","amount":5}`).Return(PublishResult{MessageID: "test-id"}, nil)
+
+		err := publisher.Publish(testOrderEvent{ID: "1", Amount: 5})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("PublishWithAttributes publishes the event as JSON with its attributes", func(t *testing.T) {
+		attributes := map[string]string{"key": "value"}
+		topicPublisher.EXPECT().PublishEventWithAttributesCtx(context.Background(), `{"id":"1","amount":5}`, attributes).Return(PublishResult{}, nil)
+
+		err := publisher.PublishWithAttributes(testOrderEvent{ID: "1", Amount: 5}, attributes)
+
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,87 @@
+package zaws
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const DefaultMaxVisibilityExtension = MaxVisibilityTimeout
+
+// startVisibilityHeartbeat keeps extending the visibility timeout of message until the returned stop function is
+// called, ctx is done or the maximum extension is reached. The listener passes its handlersContext, so that the
+// heartbeat outlives the receive context while handlers drain. It does nothing when no heartbeat interval is configured.
+func (l *SQSListener) startVisibilityHeartbeat(ctx context.Context, message types.Message) (stop func()) {
+	if l.visibilityHeartbeatInterval <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		l.extendVisibility(ctx, message)
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func (l *SQSListener) extendVisibility(ctx context.Context, message types.Message) {
+	extension := l.visibilityExtensionTimeout()
+	deadline := time.Now().Add(l.maxVisibilityExtensionDuration())
+	ticker := time.NewTicker(l.visibilityHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			remaining := deadline.Sub(now)
+			if remaining <= 0 {
+				l.logger.Warnw("visibility timeout extension limit reached",
+					"messageId", aws.ToString(message.MessageId),
+				)
+				return
+			}
+			if extension > remaining {
+				extension = remaining
+			}
+
+			_, err := l.sqsClient.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          l.queueURL,
+				ReceiptHandle:     message.ReceiptHandle,
+				VisibilityTimeout: int32(math.Ceil(extension.Seconds())),
+			})
+			if err != nil && ctx.Err() == nil {
+				l.logger.Warnw("failed to extend visibility timeout",
+					"messageId", aws.ToString(message.MessageId),
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+func (l *SQSListener) visibilityExtensionTimeout() time.Duration {
+	if l.visibilityExtension <= 0 {
+		return 2 * l.visibilityHeartbeatInterval
+	}
+	return l.visibilityExtension
+}
+
+func (l *SQSListener) maxVisibilityExtensionDuration() time.Duration {
+	if l.maxVisibilityExtension <= 0 || l.maxVisibilityExtension > DefaultMaxVisibilityExtension {
+		return DefaultMaxVisibilityExtension
+	}
+	return l.maxVisibilityExtension
+}
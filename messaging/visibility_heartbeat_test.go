@@ -0,0 +1,137 @@
+package zaws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQSListener_startVisibilityHeartbeat(t *testing.T) {
+	message := types.Message{
+		MessageId:     aws.String("test-id"),
+		ReceiptHandle: aws.String("test handle"),
+	}
+
+	t.Run("startVisibilityHeartbeat does nothing when no interval is configured", func(t *testing.T) {
+		sut := getTestListener()
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.EXPECT().ChangeMessageVisibility(gomock.Any(), gomock.Any()).Times(0)
+
+		stop := sut.startVisibilityHeartbeat(context.Background(), message)
+		time.Sleep(50 * time.Millisecond)
+		stop()
+	})
+
+	t.Run("startVisibilityHeartbeat extends the visibility timeout until stopped", func(t *testing.T) {
+		sut := getTestListener()
+		sut.visibilityHeartbeatInterval = 20 * time.Millisecond
+		sut.visibilityExtension = 30 * time.Second
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), &sqs.ChangeMessageVisibilityInput{
+				QueueUrl:          sut.queueURL,
+				ReceiptHandle:     aws.String("test handle"),
+				VisibilityTimeout: 30,
+			}).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil).
+			MinTimes(2)
+
+		stop := sut.startVisibilityHeartbeat(context.Background(), message)
+		time.Sleep(70 * time.Millisecond)
+		stop()
+	})
+
+	t.Run("startVisibilityHeartbeat stops once the maximum extension is reached", func(t *testing.T) {
+		sut := getTestListener()
+		sut.visibilityHeartbeatInterval = 20 * time.Millisecond
+		sut.maxVisibilityExtension = 30 * time.Millisecond
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), gomock.Any()).
+			Return(&sqs.ChangeMessageVisibilityOutput{}, nil).
+			Times(1)
+
+		stop := sut.startVisibilityHeartbeat(context.Background(), message)
+		time.Sleep(100 * time.Millisecond)
+		stop()
+	})
+
+	t.Run("startVisibilityHeartbeat stops when its context is cancelled", func(t *testing.T) {
+		sut := getTestListener()
+		sut.visibilityHeartbeatInterval = 20 * time.Millisecond
+		sqsClient := NewMockISQSClient(gomock.NewController(t))
+		sut.sqsClient = sqsClient
+		sqsClient.EXPECT().ChangeMessageVisibility(gomock.Any(), gomock.Any()).Times(0)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		stop := sut.startVisibilityHeartbeat(ctx, message)
+		cancel()
+		time.Sleep(50 * time.Millisecond)
+		stop()
+	})
+}
+
+func TestSQSListener_Run_visibilityHeartbeat(t *testing.T) {
+	t.Run("Run keeps extending the visibility of in-flight messages after its context is cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sut := getTestListener()
+		sut.gracefulShutdownManager = nil
+		sut.visibilityHeartbeatInterval = 10 * time.Millisecond
+		sqsClient := NewMockISQSClient(ctrl)
+		sut.sqsClient = sqsClient
+		started := make(chan struct{})
+		extended := make(chan struct{}, 1)
+		sut.handler = MessageHandlerFunc(func(types.Message) error {
+			close(started)
+			select {
+			case <-extended:
+			case <-time.After(time.Second):
+				t.Error("visibility was not extended after the listener context was cancelled")
+			}
+			return nil
+		})
+		message := types.Message{MessageId: aws.String("test-id"), ReceiptHandle: aws.String("test handle")}
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			Return(&sqs.ReceiveMessageOutput{Messages: []types.Message{message}}, nil)
+		sqsClient.
+			EXPECT().
+			ReceiveMessage(gomock.Any(), gomock.Any()).
+			DoAndReturn(blockUntilCancelled).
+			AnyTimes()
+		ctx, cancel := context.WithCancel(context.Background())
+		sqsClient.
+			EXPECT().
+			ChangeMessageVisibility(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(context.Context, *sqs.ChangeMessageVisibilityInput, ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+				if ctx.Err() != nil {
+					select {
+					case extended <- struct{}{}:
+					default:
+					}
+				}
+				return &sqs.ChangeMessageVisibilityOutput{}, nil
+			}).
+			AnyTimes()
+		sqsClient.EXPECT().DeleteMessage(gomock.Any(), gomock.Any()).Return(&sqs.DeleteMessageOutput{}, nil)
+		runErr := make(chan error)
+		go func() { runErr <- sut.Run(ctx) }()
+		<-started
+
+		cancel()
+
+		assert.ErrorIs(t, <-runErr, context.Canceled)
+	})
+}
@@ -0,0 +1,72 @@
+package zaws
+
+import (
+	"context"
+	"sync"
+)
+
+// workerPool runs jobs on a fixed number of goroutines. Callers reserve workers before fetching work so that
+// nothing is received while every worker is busy.
+type workerPool struct {
+	slots chan struct{}
+	jobs  chan func()
+	wg    sync.WaitGroup
+}
+
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{
+		slots: make(chan struct{}, size),
+		jobs:  make(chan func(), size),
+	}
+	p.wg.Add(size)
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+	return p
+}
+
+func (p *workerPool) work() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		job()
+		<-p.slots
+	}
+}
+
+// reserve blocks until at least one worker is free and reserves up to max workers.
+func (p *workerPool) reserve(ctx context.Context, max int) (int, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	reserved := 1
+	for reserved < max {
+		select {
+		case p.slots <- struct{}{}:
+			reserved++
+		default:
+			return reserved, nil
+		}
+	}
+	return reserved, nil
+}
+
+// release gives back reserved workers that were not used.
+func (p *workerPool) release(n int) {
+	for i := 0; i < n; i++ {
+		<-p.slots
+	}
+}
+
+// submit runs job on a previously reserved worker.
+func (p *workerPool) submit(job func()) {
+	p.jobs <- job
+}
+
+// stop waits for submitted jobs to finish and stops the workers.
+func (p *workerPool) stop() {
+	close(p.jobs)
+	p.wg.Wait()
+}
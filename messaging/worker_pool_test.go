@@ -0,0 +1,79 @@
+package zaws
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool_reserve(t *testing.T) {
+	t.Run("reserve returns at most the number of free workers", func(t *testing.T) {
+		pool := newWorkerPool(3)
+		defer pool.stop()
+
+		reserved, err := pool.reserve(context.Background(), 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, reserved)
+	})
+
+	t.Run("reserve returns at most the requested number of workers", func(t *testing.T) {
+		pool := newWorkerPool(3)
+		defer pool.stop()
+
+		reserved, err := pool.reserve(context.Background(), 2)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, reserved)
+	})
+
+	t.Run("reserve blocks while every worker is busy and returns the context error when cancelled", func(t *testing.T) {
+		pool := newWorkerPool(1)
+		defer pool.stop()
+		_, _ = pool.reserve(context.Background(), 1)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		reserved, err := pool.reserve(ctx, 1)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 0, reserved)
+		pool.release(1)
+	})
+
+	t.Run("reserve succeeds again once a submitted job has finished", func(t *testing.T) {
+		pool := newWorkerPool(1)
+		defer pool.stop()
+		_, _ = pool.reserve(context.Background(), 1)
+		pool.submit(func() {})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		reserved, err := pool.reserve(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, reserved)
+		pool.release(reserved)
+	})
+}
+
+func TestWorkerPool_stop(t *testing.T) {
+	t.Run("stop waits for submitted jobs to finish", func(t *testing.T) {
+		pool := newWorkerPool(2)
+		var finished int32
+		reserved, _ := pool.reserve(context.Background(), 2)
+		for i := 0; i < reserved; i++ {
+			pool.submit(func() {
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&finished, 1)
+			})
+		}
+
+		pool.stop()
+
+		assert.Equal(t, int32(2), atomic.LoadInt32(&finished))
+	})
+}